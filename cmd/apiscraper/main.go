@@ -3,17 +3,78 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"log"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/app/apiscraper"
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/apiscraper/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
 )
 
 func main() {
+	retailers := flag.String(
+		"retailer",
+		"",
+		"comma-separated retailer IDs to scrape, e.g. atacadao,assai (default all)",
+	)
+	resume := flag.String(
+		"resume",
+		"",
+		"ID of an unfinished run to continue from where it stopped",
+	)
+	retry := flag.Bool(
+		"retry",
+		false,
+		"request again the pages that failed in previous runs",
+	)
+
+	// Flags named after an environment variable override it.
+	flag.Int(
+		"api-category-concurrency",
+		0,
+		"categories requested at once (default from API_CATEGORY_CONCURRENCY)",
+	)
+	flag.Int(
+		"api-page-concurrency",
+		0,
+		"pages of each category requested at once (default from API_PAGE_CONCURRENCY)",
+	)
+	flag.Int(
+		"api-page-size",
+		0,
+		"products per page, at most 100 (default from API_PAGE_SIZE)",
+	)
+	flag.Int(
+		"api-retry-concurrency",
+		0,
+		"failed pages requested again at once (default from API_RETRY_CONCURRENCY)",
+	)
+	flag.Float64(
+		"http-requests-per-second",
+		0,
+		"maximum rate of requests (default from HTTP_REQUESTS_PER_SECOND)",
+	)
+	flag.Duration(
+		"host-delay",
+		0,
+		"minimum delay between requests to the same host, e.g. 500ms (default from HOST_DELAY)",
+	)
+	flag.Int(
+		"request-budget",
+		0,
+		"maximum number of requests of the run (default from REQUEST_BUDGET)",
+	)
+	flag.Parse()
+	if err := env.OverrideFromFlags(flag.CommandLine); err != nil {
+		log.Fatalf("failed to override environment variables: %v", err)
+	}
+
 	ctx, cancel := signal.NotifyContext(
 		context.Background(),
 		syscall.SIGINT,
@@ -25,7 +86,18 @@ func main() {
 		defer cancel()
 
 		as := apiscraper.New()
-		if err := as.Run(ctx); err != nil {
+		if *retry {
+			opts := handler.RetryOptions{
+				RetailerIDs: parseList(*retailers),
+			}
+			return as.Retry(ctx, opts)
+		}
+
+		opts := handler.RunOptions{
+			RetailerIDs: parseList(*retailers),
+			ResumeRunID: *resume,
+		}
+		if err := as.Run(ctx, opts); err != nil {
 			return err
 		}
 
@@ -54,3 +126,13 @@ func handleError(err error) {
 
 	log.Printf("failed to run: %v", err)
 }
+
+func parseList(s string) []string {
+	list := []string{}
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/matcher"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+)
+
+func main() {
+	list := flag.Bool(
+		"list",
+		false,
+		"list the uncertain matches waiting to be reviewed",
+	)
+	confirm := flag.String(
+		"confirm",
+		"",
+		"comma-separated IDs of uncertain matches to confirm",
+	)
+	reject := flag.String(
+		"reject",
+		"",
+		"comma-separated IDs of uncertain matches to reject",
+	)
+	normalize := flag.String(
+		"normalize",
+		"",
+		"comma-separated IDs of retailers whose products to normalize again, after the parsing of names changed",
+	)
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(
+		context.Background(),
+		syscall.SIGINT,
+		syscall.SIGTERM,
+	)
+
+	g := errgroup.Group{}
+	g.Go(func() error {
+		defer cancel()
+
+		m := matcher.New()
+		switch {
+		case *list:
+			return m.List(ctx)
+		case *confirm != "":
+			return m.Confirm(ctx, parseList(*confirm))
+		case *reject != "":
+			return m.Reject(ctx, parseList(*reject))
+		case *normalize != "":
+			return m.Normalize(ctx, parseList(*normalize))
+		}
+
+		if err := m.Match(ctx); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	<-ctx.Done()
+
+	if err := g.Wait(); err != nil {
+		handleError(err)
+	}
+}
+
+func handleError(err error) {
+	var appErr *errs.Err
+	if errors.As(err, &appErr) {
+		log.Printf(
+			"failed to run: %v\nstacktrace: %v",
+			appErr.Error(),
+			appErr.StackTrace,
+		)
+		return
+	}
+
+	log.Printf("failed to run: %v", err)
+}
+
+func parseList(s string) []string {
+	list := []string{}
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
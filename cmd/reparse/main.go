@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/apiscraper"
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/apiscraper/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+)
+
+func main() {
+	retailers := flag.String(
+		"retailer",
+		"",
+		"comma-separated retailer IDs to reparse, e.g. atacadao,assai (default all)",
+	)
+	runs := flag.String(
+		"run",
+		"",
+		"comma-separated IDs of the runs to reparse (default all)",
+	)
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(
+		context.Background(),
+		syscall.SIGINT,
+		syscall.SIGTERM,
+	)
+
+	g := errgroup.Group{}
+	g.Go(func() error {
+		defer cancel()
+
+		as := apiscraper.New()
+		opts := handler.ReparseOptions{
+			RetailerIDs: parseList(*retailers),
+			RunIDs:      parseList(*runs),
+		}
+		if err := as.Reparse(ctx, opts); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	<-ctx.Done()
+
+	log.Println("Shutting down...")
+
+	if err := g.Wait(); err != nil {
+		handleError(err)
+	}
+}
+
+func handleError(err error) {
+	var appErr *errs.Err
+	if errors.As(err, &appErr) {
+		log.Printf(
+			"failed to run: %v\nstacktrace: %v",
+			appErr.Error(),
+			appErr.StackTrace,
+		)
+		return
+	}
+
+	log.Printf("failed to run: %v", err)
+}
+
+func parseList(s string) []string {
+	list := []string{}
+	for item := range strings.SplitSeq(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
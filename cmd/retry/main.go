@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"errors"
+	"flag"
 	"log"
 	"os/signal"
 	"syscall"
@@ -10,10 +11,32 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/app/webscraper"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
 )
 
 func main() {
+	// Flags named after an environment variable override it.
+	flag.Int(
+		"web-retry-concurrency",
+		0,
+		"failed pages loaded again at once (default from WEB_RETRY_CONCURRENCY)",
+	)
+	flag.Duration(
+		"host-delay",
+		0,
+		"minimum delay between requests to the same host, e.g. 500ms (default from HOST_DELAY)",
+	)
+	flag.Int(
+		"request-budget",
+		0,
+		"maximum number of requests of the run (default from REQUEST_BUDGET)",
+	)
+	flag.Parse()
+	if err := env.OverrideFromFlags(flag.CommandLine); err != nil {
+		log.Fatalf("failed to override environment variables: %v", err)
+	}
+
 	ctx, cancel := signal.NotifyContext(
 		context.Background(),
 		syscall.SIGINT,
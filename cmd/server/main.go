@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log"
+	"os/signal"
+	"syscall"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/server"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+)
+
+func main() {
+	addr := flag.String(
+		"addr",
+		":8080",
+		"address the API listens on",
+	)
+	flag.Parse()
+
+	ctx, cancel := signal.NotifyContext(
+		context.Background(),
+		syscall.SIGINT,
+		syscall.SIGTERM,
+	)
+
+	g := errgroup.Group{}
+	g.Go(func() error {
+		defer cancel()
+
+		s := server.New()
+		if err := s.Serve(ctx, *addr); err != nil {
+			return err
+		}
+
+		return nil
+	})
+
+	<-ctx.Done()
+
+	log.Println("Shutting down...")
+
+	if err := g.Wait(); err != nil {
+		handleError(err)
+	}
+}
+
+func handleError(err error) {
+	var appErr *errs.Err
+	if errors.As(err, &appErr) {
+		log.Printf(
+			"failed to run: %v\nstacktrace: %v",
+			appErr.Error(),
+			appErr.StackTrace,
+		)
+		return
+	}
+
+	log.Printf("failed to run: %v", err)
+}
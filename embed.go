@@ -6,3 +6,6 @@ import (
 
 //go:embed .env*
 var Env embed.FS
+
+//go:embed selectors
+var Selectors embed.FS
@@ -1,6 +1,12 @@
 package apiscraper
 
-import "github.com/danielmesquitta/supermarket-scraper/internal/app/apiscraper/handler"
+import (
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/apiscraper/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/ratelimit"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi/atacadaoapi"
+)
 
 type APIScraper struct {
 	*handler.Handler
@@ -13,3 +19,19 @@ func Build(
 		Handler: h,
 	}
 }
+
+// NewRegistry lists every retailer the API scraper can run.
+func NewRegistry(
+	atacadao *atacadaoapi.AtacadaoAPI,
+) *supermarketapi.Registry {
+	return supermarketapi.NewRegistry(
+		atacadao,
+	)
+}
+
+// NewGate returns the gate every request of the API scraper goes through.
+func NewGate(
+	e *env.Env,
+) *ratelimit.Gate {
+	return ratelimit.NewGate(e.HostDelay, e.RequestBudget)
+}
@@ -3,26 +3,72 @@ package handler
 import (
 	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
 	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
 )
 
 type Handler struct {
-	e    *env.Env
-	sa   supermarketapi.SupermarketAPI
-	spuc *usecase.SaveProductsUseCase
-	seuc *usecase.SaveErrorUseCase
+	e     *env.Env
+	db    db.DB
+	r     *supermarketapi.Registry
+	sruc  *usecase.SaveRetailerUseCase
+	ssuc  *usecase.SyncStoresUseCase
+	scuc  *usecase.SyncCategoriesUseCase
+	ssruc *usecase.StartScrapeRunUseCase
+	rsruc *usecase.ResumeScrapeRunUseCase
+	fsruc *usecase.FinishScrapeRunUseCase
+	crhuc *usecase.CheckRunHealthUseCase
+	dpuc  *usecase.DelistProductsUseCase
+	apcuc *usecase.AlertPriceChangesUseCase
+	aduc  *usecase.AlertDelistingsUseCase
+	srouc *usecase.SaveRetryOutcomesUseCase
+	sscuc *usecase.SaveScrapeCheckpointUseCase
+	srpuc *usecase.SaveRawPayloadUseCase
+	lrpuc *usecase.ListRawPayloadsUseCase
+	spuc  *usecase.SaveProductsUseCase
+	seuc  *usecase.SaveErrorUseCase
 }
 
 func New(
 	e *env.Env,
-	sa supermarketapi.SupermarketAPI,
+	db db.DB,
+	r *supermarketapi.Registry,
+	sruc *usecase.SaveRetailerUseCase,
+	ssuc *usecase.SyncStoresUseCase,
+	scuc *usecase.SyncCategoriesUseCase,
+	ssruc *usecase.StartScrapeRunUseCase,
+	rsruc *usecase.ResumeScrapeRunUseCase,
+	fsruc *usecase.FinishScrapeRunUseCase,
+	crhuc *usecase.CheckRunHealthUseCase,
+	dpuc *usecase.DelistProductsUseCase,
+	apcuc *usecase.AlertPriceChangesUseCase,
+	aduc *usecase.AlertDelistingsUseCase,
+	srouc *usecase.SaveRetryOutcomesUseCase,
+	sscuc *usecase.SaveScrapeCheckpointUseCase,
+	srpuc *usecase.SaveRawPayloadUseCase,
+	lrpuc *usecase.ListRawPayloadsUseCase,
 	spuc *usecase.SaveProductsUseCase,
 	seuc *usecase.SaveErrorUseCase,
 ) *Handler {
 	return &Handler{
-		e:    e,
-		sa:   sa,
-		spuc: spuc,
-		seuc: seuc,
+		e:     e,
+		db:    db,
+		r:     r,
+		sruc:  sruc,
+		ssuc:  ssuc,
+		scuc:  scuc,
+		ssruc: ssruc,
+		rsruc: rsruc,
+		fsruc: fsruc,
+		crhuc: crhuc,
+		dpuc:  dpuc,
+		apcuc: apcuc,
+		aduc:  aduc,
+		srouc: srouc,
+		sscuc: sscuc,
+		srpuc: srpuc,
+		lrpuc: lrpuc,
+		spuc:  spuc,
+		seuc:  seuc,
 	}
 }
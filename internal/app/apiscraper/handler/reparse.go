@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
+)
+
+type ReparseOptions struct {
+	// RetailerIDs selects the retailers to reparse, all of them when empty.
+	RetailerIDs []string
+	// RunIDs selects the runs to reparse, every run of the retailers when
+	// empty.
+	RunIDs []string
+}
+
+// Reparse fills in the attributes the products lack from the payloads stored
+// by previous runs, such as those the API returned but was not read from it
+// when they were fetched. Payloads may be older than the catalog, so the
+// attributes the products have are kept, the latest runs are reparsed first
+// and no product is created or relisted. No price is observed, as prices
+// were observed when the payloads were fetched.
+func (h *Handler) Reparse(ctx context.Context, opts ReparseOptions) error {
+	apis, err := h.r.Select(opts.RetailerIDs)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	missingRunIDs := map[string]bool{}
+	for _, runID := range opts.RunIDs {
+		missingRunIDs[runID] = true
+	}
+
+	for _, api := range apis {
+		err := h.reparseRetailer(ctx, api, opts.RunIDs, missingRunIDs)
+		if err != nil {
+			return errs.New(err)
+		}
+	}
+
+	if len(missingRunIDs) > 0 {
+		return errs.New(
+			fmt.Sprintf(
+				"scrape runs not found: %s",
+				strings.Join(slices.Sorted(maps.Keys(missingRunIDs)), ", "),
+			),
+		)
+	}
+
+	return nil
+}
+
+func (h *Handler) reparseRetailer(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+	runIDs []string,
+	missingRunIDs map[string]bool,
+) error {
+	retailerID := api.Retailer().ID
+
+	runs, err := h.db.ListScrapeRuns(ctx, retailerID)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	categories, err := h.db.ListCategories(ctx, retailerID)
+	if err != nil {
+		return errs.New(err)
+	}
+	categoryIDs := map[string]string{}
+	for _, category := range categories {
+		categoryIDs[category.Path] = category.ID
+	}
+
+	for _, run := range slices.Backward(runs) {
+		if len(runIDs) > 0 && !slices.Contains(runIDs, run.ID) {
+			continue
+		}
+		delete(missingRunIDs, run.ID)
+
+		if err := ctx.Err(); err != nil {
+			return errs.New(err)
+		}
+
+		pageCount, productCount := 0, 0
+		err := h.lrpuc.Execute(
+			ctx,
+			run.ID,
+			func(payload entity.RawPayload) error {
+				req := supermarketapi.PageRequest{
+					Category: payload.Category,
+					Page:     payload.Page,
+					Size:     payload.Size,
+				}
+
+				page, err := api.ParsePage(req, payload.Body)
+				if err != nil {
+					slog.Warn(
+						"skipping payload that cannot be parsed",
+						"id", payload.ID,
+						"err", err,
+					)
+					return nil
+				}
+
+				for i := range page.Products {
+					if id, ok := categoryIDs[payload.Category]; ok {
+						page.Products[i].CategoryID = &id
+					}
+				}
+
+				_, err = h.spuc.Execute(ctx, nil, page.Products)
+				if err != nil {
+					return errs.New(err)
+				}
+				pageCount++
+				productCount += len(page.Products)
+
+				return nil
+			},
+		)
+		if err != nil {
+			return errs.New(err)
+		}
+
+		slog.Info(
+			"scrape run reparsed",
+			"run_id", run.ID,
+			"retailer_id", retailerID,
+			"pages", pageCount,
+			"products", productCount,
+		)
+	}
+
+	return nil
+}
@@ -1 +1,243 @@
 package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
+)
+
+type RetryOptions struct {
+	// RetailerIDs selects the retailers to retry, all of them when empty.
+	RetailerIDs []string
+}
+
+type pageRequestMetadata struct {
+	RetailerID string `json:"retailer_id"`
+	StoreID    string `json:"store_id"`
+	Category   string `json:"category"`
+	Page       int    `json:"page"`
+	Size       int    `json:"size"`
+}
+
+// pageKey identifies a page of products of a run.
+type pageKey struct {
+	category string
+	page     int
+}
+
+// Retry requests again every page that failed in a previous run. The errors
+// of the pages that now succeed are soft-deleted, while the others count
+// one more failed attempt.
+func (h *Handler) Retry(ctx context.Context, opts RetryOptions) error {
+	dbErrors, err := h.db.ListErrorsByType(
+		ctx,
+		errs.ErrTypeFailedRequestingProductsPage,
+	)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	apis, err := h.r.Select(opts.RetailerIDs)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	errorsByRetailer := map[string][]entity.Error{}
+	for _, dbError := range dbErrors {
+		var metadata pageRequestMetadata
+		err := json.Unmarshal([]byte(dbError.Metadata), &metadata)
+		if err != nil || metadata.Category == "" || metadata.Size < 1 {
+			slog.Warn("skipping error with invalid metadata", "id", dbError.ID)
+			continue
+		}
+		errorsByRetailer[metadata.RetailerID] = append(
+			errorsByRetailer[metadata.RetailerID],
+			dbError,
+		)
+	}
+
+	g := errgroup.Group{}
+	for _, api := range apis {
+		dbErrors := errorsByRetailer[api.Retailer().ID]
+		if len(dbErrors) == 0 {
+			continue
+		}
+
+		g.Go(func() error {
+			return h.retryRetailer(ctx, api, dbErrors)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (h *Handler) retryRetailer(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+	dbErrors []entity.Error,
+) error {
+	retailer := api.Retailer()
+
+	categories, err := h.db.ListCategories(ctx, retailer.ID)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	stores, err := h.db.ListStores(ctx, retailer.ID)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	errorsByStore := map[string][]entity.Error{}
+	for _, dbError := range dbErrors {
+		var metadata pageRequestMetadata
+		_ = json.Unmarshal([]byte(dbError.Metadata), &metadata)
+		errorsByStore[metadata.StoreID] = append(
+			errorsByStore[metadata.StoreID],
+			dbError,
+		)
+	}
+
+	var retryErrs []error
+	for _, store := range stores {
+		dbErrors := errorsByStore[store.ID]
+		if len(dbErrors) == 0 {
+			continue
+		}
+		delete(errorsByStore, store.ID)
+
+		err := h.retryStore(ctx, api, store, categories, dbErrors)
+		if err != nil {
+			retryErrs = append(retryErrs, err)
+		}
+	}
+
+	for storeID, dbErrors := range errorsByStore {
+		slog.Warn(
+			"skipping errors of unknown store",
+			"retailer_id", retailer.ID,
+			"store_id", storeID,
+			"errors", len(dbErrors),
+		)
+	}
+
+	if err := errors.Join(retryErrs...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (h *Handler) retryStore(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+	store entity.Store,
+	categories []entity.Category,
+	dbErrors []entity.Error,
+) (err error) {
+	var run *usecase.RunTracker
+	run, err = h.ssruc.Execute(ctx, usecase.StartScrapeRunOptions{
+		RetailerID: store.RetailerID,
+		StoreID:    &store.ID,
+		Source:     entity.SourceAPI,
+		Kind:       entity.RunKindRetry,
+	})
+	if err != nil {
+		return errs.New(err)
+	}
+	run.SetCategories(categories)
+
+	mu := sync.Mutex{}
+	outcomes := []usecase.RetryOutcome{}
+
+	defer func() {
+		// The outcomes must be stored and the run closed even if the retry
+		// was cancelled.
+		ctx := context.WithoutCancel(ctx)
+		if saveErr := h.srouc.Execute(ctx, outcomes); saveErr != nil {
+			err = errors.Join(err, saveErr)
+		}
+		if finishErr := h.fsruc.Execute(ctx, run, err); finishErr != nil {
+			err = errors.Join(err, finishErr)
+		}
+		slog.Info(
+			"retry run finished",
+			"run_id", run.ID,
+			"retailer_id", run.RetailerID,
+			"store", store.Code,
+			"retried", len(outcomes),
+			"errors", run.ErrorCount,
+		)
+	}()
+
+	g, gCtx := errgroup.WithContext(ctx)
+	g.SetLimit(h.e.APIRetryConcurrency)
+	for _, dbError := range dbErrors {
+		g.Go(func() error {
+			err := h.retryError(gCtx, api, run, store, dbError)
+			if ctxErr := gCtx.Err(); ctxErr != nil {
+				return errs.New(ctxErr)
+			}
+
+			mu.Lock()
+			outcomes = append(outcomes, usecase.RetryOutcome{
+				Error: dbError,
+				Err:   err,
+			})
+			mu.Unlock()
+
+			return nil
+		})
+	}
+
+	if err = g.Wait(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (h *Handler) retryError(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+	run *usecase.RunTracker,
+	store entity.Store,
+	dbError entity.Error,
+) error {
+	var metadata pageRequestMetadata
+	if err := json.Unmarshal([]byte(dbError.Metadata), &metadata); err != nil {
+		return errs.New(err)
+	}
+
+	req := supermarketapi.PageRequest{
+		Store:    store,
+		Category: metadata.Category,
+		Page:     metadata.Page,
+		Size:     metadata.Size,
+	}
+
+	page, err := api.ListProductsPage(ctx, req)
+	if err != nil {
+		run.AddError(req.Category)
+		return errs.New(err)
+	}
+
+	if err := h.savePage(ctx, run, *page); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
@@ -2,29 +2,393 @@ package handler
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 
+	"golang.org/x/sync/errgroup"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
 )
 
-func (h *Handler) Run(ctx context.Context) error {
-	products, err := h.sa.ListProducts(ctx)
+type RunOptions struct {
+	// RetailerIDs selects the retailers to scrape, all of them when empty.
+	RetailerIDs []string
+	// ResumeRunID continues an unfinished run instead of starting new ones.
+	ResumeRunID string
+}
+
+func (h *Handler) Run(ctx context.Context, opts RunOptions) error {
+	if opts.ResumeRunID != "" {
+		return h.resume(ctx, opts.ResumeRunID)
+	}
+
+	apis, err := h.r.Select(opts.RetailerIDs)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	g := errgroup.Group{}
+	for _, api := range apis {
+		g.Go(func() error {
+			return h.runRetailer(ctx, api)
+		})
+	}
+
+	if err := g.Wait(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (h *Handler) runRetailer(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+) error {
+	retailer := api.Retailer()
+
+	if err := h.sruc.Execute(ctx, retailer); err != nil {
+		return errs.New(err)
+	}
+
+	stores, err := h.ssuc.Execute(ctx, api)
+	if err != nil {
+		_ = h.seuc.Execute(
+			ctx,
+			"",
+			errs.New(err, errs.ErrTypeFailedListingStores),
+			map[string]any{"retailer_id": retailer.ID},
+		)
+		return errs.New(err)
+	}
+
+	// Stores are crawled one after the other, each in a run of its own, so
+	// that a run can be resumed or retried for its store alone.
+	var crawlErrs []error
+	for _, store := range stores {
+		if err := ctx.Err(); err != nil {
+			return errs.New(err)
+		}
+
+		run, err := h.ssruc.Execute(ctx, usecase.StartScrapeRunOptions{
+			RetailerID: retailer.ID,
+			StoreID:    &store.ID,
+			Source:     entity.SourceAPI,
+			Kind:       entity.RunKindCrawl,
+			PageSize:   h.e.APIPageSize,
+		})
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if err := h.crawl(ctx, api, run, store, nil, nil); err != nil {
+			crawlErrs = append(crawlErrs, err)
+		}
+	}
+
+	if err := errors.Join(crawlErrs...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (h *Handler) resume(ctx context.Context, runID string) error {
+	run, checkpoints, err := h.rsruc.Execute(ctx, runID, h.e.APIPageSize)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if run.Source != string(entity.SourceAPI) {
+		return errs.New(
+			fmt.Sprintf("scrape run %s was not made by the API scraper", runID),
+		)
+	}
+
+	api, err := h.r.Get(run.RetailerID)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	store, err := h.getStore(ctx, run.RetailerID, run.StoreID)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	dbErrors, err := h.db.ListErrorsByType(
+		ctx,
+		errs.ErrTypeFailedRequestingProductsPage,
+	)
+	if err != nil {
+		return errs.New(err)
+	}
+	pageErrors := []entity.Error{}
+	for _, dbError := range dbErrors {
+		if dbError.RunID != nil && *dbError.RunID == run.ID {
+			pageErrors = append(pageErrors, dbError)
+		}
+	}
+
+	return h.crawl(ctx, api, run, *store, checkpoints, pageErrors)
+}
+
+func (h *Handler) getStore(
+	ctx context.Context,
+	retailerID string,
+	storeID *string,
+) (*entity.Store, error) {
+	if storeID == nil {
+		return nil, errs.New("scrape run has no store")
+	}
+
+	stores, err := h.db.ListStores(ctx, retailerID)
 	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	for _, store := range stores {
+		if store.ID == *storeID {
+			return &store, nil
+		}
+	}
+
+	return nil, errs.New(fmt.Sprintf("store %s not found", *storeID))
+}
+
+// crawl saves every page of products as soon as it is fetched and
+// checkpoints it, so the run can be resumed if it stops halfway. Once the
+// whole catalog is crawled, the products it no longer lists are delisted.
+//
+// A resumed run requests again the pages whose errors it recorded before,
+// given as pageErrors. Like a retry, it soft-deletes the errors of the pages
+// that now succeed and counts one more failed attempt for the others,
+// instead of recording them again.
+func (h *Handler) crawl(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+	run *usecase.RunTracker,
+	store entity.Store,
+	checkpoints []entity.ScrapeCheckpoint,
+	pageErrors []entity.Error,
+) (err error) {
+	slog.Info(
+		"scrape run started",
+		"run_id", run.ID,
+		"retailer_id", run.RetailerID,
+		"store", store.Code,
+		"resumed_pages", len(checkpoints),
+	)
+
+	errorsByPage := map[pageKey][]entity.Error{}
+	for _, dbError := range pageErrors {
+		var metadata pageRequestMetadata
+		_ = json.Unmarshal([]byte(dbError.Metadata), &metadata)
+		key := pageKey{category: metadata.Category, page: metadata.Page}
+		errorsByPage[key] = append(errorsByPage[key], dbError)
+	}
+
+	mu := sync.Mutex{}
+	outcomes := []usecase.RetryOutcome{}
+
+	// resolve records the outcome of requesting the page again for the
+	// errors recorded for it, reporting whether there were any.
+	resolve := func(category string, page int, err error) bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		key := pageKey{category: category, page: page}
+		dbErrors, ok := errorsByPage[key]
+		if !ok {
+			return false
+		}
+		delete(errorsByPage, key)
+
+		for _, dbError := range dbErrors {
+			outcomes = append(outcomes, usecase.RetryOutcome{
+				Error: dbError,
+				Err:   err,
+			})
+		}
+		return true
+	}
+
+	defer func() {
+		// The run must be closed even if the scrape was cancelled.
+		ctx := context.WithoutCancel(ctx)
+		if saveErr := h.srouc.Execute(ctx, outcomes); saveErr != nil {
+			err = errors.Join(err, saveErr)
+		}
+		if finishErr := h.fsruc.Execute(ctx, run, err); finishErr != nil {
+			err = errors.Join(err, finishErr)
+		}
+		if healthErr := h.crhuc.Execute(ctx, run); healthErr != nil {
+			err = errors.Join(err, healthErr)
+		}
+		delisted, delistErr := h.dpuc.Execute(ctx, run)
+		if delistErr != nil {
+			err = errors.Join(err, delistErr)
+		}
+		if alertErr := h.aduc.Execute(ctx, run, delisted); alertErr != nil {
+			_ = h.seuc.Execute(
+				ctx,
+				run.ID,
+				errs.New(alertErr, errs.ErrTypeFailedSendingAlerts),
+				map[string]any{"retailer_id": run.RetailerID},
+			)
+		}
+		slog.Info(
+			"scrape run finished",
+			"run_id", run.ID,
+			"status", run.Status,
+			"products", run.ProductCount,
+			"errors", run.ErrorCount,
+		)
+	}()
+
+	categories, err := h.scuc.Execute(ctx, api)
+	if err != nil {
+		run.AddError("")
 		_ = h.seuc.Execute(
 			ctx,
-			errs.New(err, errs.ErrTypeFailedListingProducts),
-			nil,
+			run.ID,
+			errs.New(err, errs.ErrTypeFailedListingCategories),
+			map[string]any{"retailer_id": run.RetailerID},
 		)
 		return errs.New(err)
 	}
+	run.SetCategories(categories)
+
+	// Only top-level categories are crawled, as they already include every
+	// product of their subcategories.
+	rootCategories := []string{}
+	for _, category := range categories {
+		if category.ParentID == nil {
+			rootCategories = append(rootCategories, category.Path)
+		}
+	}
+
+	// A resumed run keeps the page size its checkpoints count pages in.
+	pageSize := h.e.APIPageSize
+	if run.PageSize != nil {
+		pageSize = *run.PageSize
+	}
+
+	opts := supermarketapi.ListProductsOptions{
+		Store:       store,
+		Categories:  rootCategories,
+		Checkpoints: checkpoints,
+		PageSize:    pageSize,
+		OnPage: func(ctx context.Context, page supermarketapi.Page) error {
+			if err := h.savePage(ctx, run, page); err != nil {
+				return errs.New(err)
+			}
+			resolve(page.Category, page.Page, nil)
+			return nil
+		},
+		OnPageError: func(
+			ctx context.Context,
+			req supermarketapi.PageRequest,
+			err error,
+		) {
+			run.AddError(req.Category)
+			if resolve(req.Category, req.Page, err) {
+				return
+			}
+			_ = h.seuc.Execute(
+				ctx,
+				run.ID,
+				errs.New(err, errs.ErrTypeFailedRequestingProductsPage),
+				map[string]any{
+					"retailer_id": run.RetailerID,
+					"store_id":    req.Store.ID,
+					"category":    req.Category,
+					"page":        req.Page,
+					"size":        req.Size,
+				},
+			)
+		},
+	}
+
+	if err = api.ListProducts(ctx, opts); err != nil {
+		return errs.New(err)
+	}
 
-	if err = h.spuc.Execute(ctx, products); err != nil {
+	return nil
+}
+
+func (h *Handler) savePage(
+	ctx context.Context,
+	run *usecase.RunTracker,
+	page supermarketapi.Page,
+) error {
+	categoryID := run.CategoryID(page.Category)
+	for i := range page.Products {
+		if page.Products[i].CategoryID == nil {
+			page.Products[i].CategoryID = categoryID
+		}
+	}
+
+	observations, err := h.spuc.Execute(ctx, run, page.Products)
+	if err != nil {
+		run.AddError(page.Category)
 		_ = h.seuc.Execute(
 			ctx,
+			run.ID,
 			errs.New(err, errs.ErrTypeFailedSavingProducts),
-			nil,
+			map[string]any{
+				"retailer_id": run.RetailerID,
+				"store_id":    page.Store.ID,
+				"category":    page.Category,
+				"page":        page.Page,
+			},
 		)
 		return errs.New(err)
 	}
 
+	run.AddProducts(page.Category, page.Products)
+
+	// A failed alert does not fail the page, whose products are saved.
+	if err := h.apcuc.Execute(ctx, run, observations); err != nil {
+		_ = h.seuc.Execute(
+			ctx,
+			run.ID,
+			errs.New(err, errs.ErrTypeFailedSendingAlerts),
+			map[string]any{
+				"retailer_id": run.RetailerID,
+				"store_id":    page.Store.ID,
+				"category":    page.Category,
+				"page":        page.Page,
+			},
+		)
+	}
+
+	err = h.srpuc.Execute(ctx, entity.RawPayload{
+		RunID:    run.ID,
+		Category: page.Category,
+		Page:     page.Page,
+		Size:     page.Size,
+		Body:     page.Raw,
+	})
+	if err != nil {
+		return errs.New(err)
+	}
+
+	err = h.sscuc.Execute(ctx, entity.ScrapeCheckpoint{
+		RunID:        run.ID,
+		Category:     page.Category,
+		Page:         page.Page,
+		TotalPages:   page.TotalPages,
+		ProductCount: len(page.Products),
+	})
+	if err != nil {
+		return errs.New(err)
+	}
+
 	return nil
 }
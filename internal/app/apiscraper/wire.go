@@ -10,8 +10,8 @@ import (
 	"github.com/danielmesquitta/supermarket-scraper/internal/config"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
 	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+	notifierfactory "github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier/factory"
 	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi/atacadaoapi"
 )
 
@@ -21,17 +21,30 @@ func New() *APIScraper {
 		validator.New,
 
 		config.LoadConfig,
-
+		NewGate,
+
+		usecase.NewSaveRetailerUseCase,
+		usecase.NewSyncStoresUseCase,
+		usecase.NewSyncCategoriesUseCase,
+		usecase.NewStartScrapeRunUseCase,
+		usecase.NewResumeScrapeRunUseCase,
+		usecase.NewFinishScrapeRunUseCase,
+		usecase.NewCheckRunHealthUseCase,
+		usecase.NewDelistProductsUseCase,
+		usecase.NewAlertPriceChangesUseCase,
+		usecase.NewAlertDelistingsUseCase,
+		usecase.NewSaveRetryOutcomesUseCase,
+		usecase.NewSaveScrapeCheckpointUseCase,
+		usecase.NewSaveRawPayloadUseCase,
+		usecase.NewListRawPayloadsUseCase,
 		usecase.NewSaveProductsUseCase,
 		usecase.NewSaveErrorUseCase,
 
-		sqlite.New,
+		factory.NewDB,
+		notifierfactory.NewNotifier,
 
-		wire.Bind(
-			new(supermarketapi.SupermarketAPI),
-			new(*atacadaoapi.AtacadaoAPI),
-		),
 		atacadaoapi.New,
+		NewRegistry,
 
 		handler.New,
 
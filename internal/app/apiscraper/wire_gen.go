@@ -11,7 +11,8 @@ import (
 	"github.com/danielmesquitta/supermarket-scraper/internal/config"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
 	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+	factory2 "github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier/factory"
 	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi/atacadaoapi"
 )
 
@@ -20,11 +21,28 @@ import (
 func New() *APIScraper {
 	validation := validator.New()
 	env := config.LoadConfig(validation)
-	atacadaoAPI := atacadaoapi.New(env)
-	db := sqlite.New(env)
-	saveProductsUseCase := usecase.NewSaveProductsUseCase(db)
+	db := factory.NewDB(env)
+	gate := NewGate(env)
+	atacadaoAPI := atacadaoapi.New(env, gate)
+	registry := NewRegistry(atacadaoAPI)
+	saveRetailerUseCase := usecase.NewSaveRetailerUseCase(db)
+	syncStoresUseCase := usecase.NewSyncStoresUseCase(db)
+	syncCategoriesUseCase := usecase.NewSyncCategoriesUseCase(db)
+	startScrapeRunUseCase := usecase.NewStartScrapeRunUseCase(db)
+	resumeScrapeRunUseCase := usecase.NewResumeScrapeRunUseCase(db)
+	finishScrapeRunUseCase := usecase.NewFinishScrapeRunUseCase(db)
 	saveErrorUseCase := usecase.NewSaveErrorUseCase(db)
-	handlerHandler := handler.New(env, atacadaoAPI, saveProductsUseCase, saveErrorUseCase)
+	checkRunHealthUseCase := usecase.NewCheckRunHealthUseCase(db, saveErrorUseCase)
+	delistProductsUseCase := usecase.NewDelistProductsUseCase(db)
+	notifier := factory2.NewNotifier(env)
+	alertPriceChangesUseCase := usecase.NewAlertPriceChangesUseCase(db, notifier)
+	alertDelistingsUseCase := usecase.NewAlertDelistingsUseCase(db, notifier)
+	saveRetryOutcomesUseCase := usecase.NewSaveRetryOutcomesUseCase(env, db)
+	saveScrapeCheckpointUseCase := usecase.NewSaveScrapeCheckpointUseCase(db)
+	saveRawPayloadUseCase := usecase.NewSaveRawPayloadUseCase(db)
+	listRawPayloadsUseCase := usecase.NewListRawPayloadsUseCase(db)
+	saveProductsUseCase := usecase.NewSaveProductsUseCase(db)
+	handlerHandler := handler.New(env, db, registry, saveRetailerUseCase, syncStoresUseCase, syncCategoriesUseCase, startScrapeRunUseCase, resumeScrapeRunUseCase, finishScrapeRunUseCase, checkRunHealthUseCase, delistProductsUseCase, alertPriceChangesUseCase, alertDelistingsUseCase, saveRetryOutcomesUseCase, saveScrapeCheckpointUseCase, saveRawPayloadUseCase, listRawPayloadsUseCase, saveProductsUseCase, saveErrorUseCase)
 	apiScraper := Build(handlerHandler)
 	return apiScraper
 }
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+)
+
+type Handler struct {
+	mpuc  *usecase.MatchProductsUseCase
+	lumuc *usecase.ListUncertainMatchesUseCase
+	cmuc  *usecase.ConfirmMatchesUseCase
+	rmuc  *usecase.RejectMatchesUseCase
+	npuc  *usecase.NormalizeProductsUseCase
+}
+
+func New(
+	mpuc *usecase.MatchProductsUseCase,
+	lumuc *usecase.ListUncertainMatchesUseCase,
+	cmuc *usecase.ConfirmMatchesUseCase,
+	rmuc *usecase.RejectMatchesUseCase,
+	npuc *usecase.NormalizeProductsUseCase,
+) *Handler {
+	return &Handler{
+		mpuc:  mpuc,
+		lumuc: lumuc,
+		cmuc:  cmuc,
+		rmuc:  rmuc,
+		npuc:  npuc,
+	}
+}
+
+// Match links the products not matched yet to their canonical products.
+func (h *Handler) Match(ctx context.Context) error {
+	if err := h.mpuc.Execute(ctx); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// List prints the matches waiting to be reviewed.
+func (h *Handler) List(ctx context.Context) error {
+	matches, err := h.lumuc.Execute(ctx)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if len(matches) == 0 {
+		fmt.Println("No matches waiting to be reviewed.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "MATCH\tCONFIDENCE\tRETAILER\tPRODUCT\tCANONICAL PRODUCT")
+	for _, match := range matches {
+		_, _ = fmt.Fprintf(
+			w,
+			"%s\t%.2f\t%s\t%s\t%s\n",
+			match.Match.ID,
+			match.Match.Confidence,
+			match.Product.RetailerID,
+			match.Product.Name,
+			match.CanonicalProduct.Name,
+		)
+	}
+
+	if err := w.Flush(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// Confirm confirms the pending matches with the given IDs.
+func (h *Handler) Confirm(ctx context.Context, ids []string) error {
+	if err := h.cmuc.Execute(ctx, ids); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// Reject rejects the pending matches with the given IDs.
+func (h *Handler) Reject(ctx context.Context, ids []string) error {
+	if err := h.rmuc.Execute(ctx, ids); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// Normalize recomputes the normalized names and measures of the products of
+// the retailers with the given IDs, so they are matched and identified by the
+// current parsing of names.
+func (h *Handler) Normalize(ctx context.Context, retailerIDs []string) error {
+	for _, retailerID := range retailerIDs {
+		if err := h.npuc.Execute(ctx, retailerID); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,17 @@
+package matcher
+
+import (
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/matcher/handler"
+)
+
+type Matcher struct {
+	*handler.Handler
+}
+
+func Build(
+	h *handler.Handler,
+) *Matcher {
+	return &Matcher{
+		Handler: h,
+	}
+}
@@ -0,0 +1,36 @@
+//go:build wireinject
+// +build wireinject
+
+package matcher
+
+import (
+	"github.com/google/wire"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/matcher/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+)
+
+func New() *Matcher {
+	wire.Build(
+		wire.Bind(new(validator.Validator), new(*validator.Validation)),
+		validator.New,
+
+		config.LoadConfig,
+
+		usecase.NewMatchProductsUseCase,
+		usecase.NewListUncertainMatchesUseCase,
+		usecase.NewConfirmMatchesUseCase,
+		usecase.NewRejectMatchesUseCase,
+		usecase.NewNormalizeProductsUseCase,
+
+		factory.NewDB,
+
+		handler.New,
+
+		Build,
+	)
+	return &Matcher{}
+}
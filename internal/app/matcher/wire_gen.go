@@ -0,0 +1,31 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package matcher
+
+import (
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/matcher/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+)
+
+// Injectors from wire.go:
+
+func New() *Matcher {
+	validation := validator.New()
+	env := config.LoadConfig(validation)
+	db := factory.NewDB(env)
+	matchProductsUseCase := usecase.NewMatchProductsUseCase(db)
+	listUncertainMatchesUseCase := usecase.NewListUncertainMatchesUseCase(db)
+	confirmMatchesUseCase := usecase.NewConfirmMatchesUseCase(db)
+	rejectMatchesUseCase := usecase.NewRejectMatchesUseCase(db)
+	normalizeProductsUseCase := usecase.NewNormalizeProductsUseCase(db)
+	handlerHandler := handler.New(matchProductsUseCase, listUncertainMatchesUseCase, confirmMatchesUseCase, rejectMatchesUseCase, normalizeProductsUseCase)
+	matcher := Build(handlerHandler)
+	return matcher
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+const (
+	defaultPageSize = 50
+	maxPageSize     = 200
+)
+
+type Handler struct {
+	spuc  *usecase.SearchProductsUseCase
+	gphuc *usecase.GetProductHistoryUseCase
+	lcuc  *usecase.ListCategoriesUseCase
+	lsruc *usecase.ListScrapeRunsUseCase
+	leuc  *usecase.ListErrorsUseCase
+}
+
+func New(
+	spuc *usecase.SearchProductsUseCase,
+	gphuc *usecase.GetProductHistoryUseCase,
+	lcuc *usecase.ListCategoriesUseCase,
+	lsruc *usecase.ListScrapeRunsUseCase,
+	leuc *usecase.ListErrorsUseCase,
+) *Handler {
+	return &Handler{
+		spuc:  spuc,
+		gphuc: gphuc,
+		lcuc:  lcuc,
+		lsruc: lsruc,
+		leuc:  leuc,
+	}
+}
+
+// Serve serves the API on addr until ctx is done, then waits for the
+// requests in flight to finish.
+func (h *Handler) Serve(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /products", h.searchProducts)
+	mux.HandleFunc("GET /products/{id}", h.getProduct)
+	mux.HandleFunc("GET /categories", h.listCategories)
+	mux.HandleFunc("GET /runs", h.listRuns)
+	mux.HandleFunc("GET /errors", h.listErrors)
+
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("server listening", "addr", addr)
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		return errs.New(err)
+
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(
+			context.Background(),
+			10*time.Second,
+		)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return errs.New(err)
+		}
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			return errs.New(err)
+		}
+
+		return nil
+	}
+}
+
+// page is a page of a listing, along with how many records match in total.
+type page[T any] struct {
+	Items    []T `json:"items"`
+	Page     int `json:"page"`
+	PageSize int `json:"page_size"`
+	Total    int `json:"total"`
+}
+
+func newPage[T any](items []T, pagination db.Pagination, total int) page[T] {
+	return page[T]{
+		Items:    nonNil(items),
+		Page:     pagination.Page,
+		PageSize: pagination.PageSize,
+		Total:    total,
+	}
+}
+
+// nonNil returns the items, or an empty slice when nil, which is encoded as
+// [] rather than null.
+func nonNil[T any](items []T) []T {
+	if items == nil {
+		return []T{}
+	}
+	return items
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// writeError answers with the message of the error, unless it is an internal
+// one, which is only logged.
+func writeError(w http.ResponseWriter, status int, err error) {
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		slog.Error("request failed", "err", err)
+		message = http.StatusText(status)
+	}
+
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// queryParser parses the query parameters of a request, keeping the first
+// invalid one as its error.
+type queryParser struct {
+	values url.Values
+	err    error
+}
+
+func newQueryParser(r *http.Request) *queryParser {
+	return &queryParser{
+		values: r.URL.Query(),
+	}
+}
+
+func (p *queryParser) string(key string) string {
+	return p.values.Get(key)
+}
+
+func (p *queryParser) int(key string, fallback, minimum, maximum int) int {
+	s := p.values.Get(key)
+	if s == "" {
+		return fallback
+	}
+
+	n, err := strconv.Atoi(s)
+	if err != nil || n < minimum || n > maximum {
+		p.fail(
+			fmt.Errorf("%s must be an integer from %d to %d", key, minimum, maximum),
+		)
+		return fallback
+	}
+
+	return n
+}
+
+func (p *queryParser) float(key string) *float64 {
+	s := p.values.Get(key)
+	if s == "" {
+		return nil
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		p.fail(fmt.Errorf("%s must be a number", key))
+		return nil
+	}
+
+	return &n
+}
+
+func (p *queryParser) bool(key string) bool {
+	s := p.values.Get(key)
+	if s == "" {
+		return false
+	}
+
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		p.fail(fmt.Errorf("%s must be true or false", key))
+		return false
+	}
+
+	return b
+}
+
+// time parses a date, e.g. 2025-06-30, or a RFC 3339 timestamp.
+func (p *queryParser) time(key string) *time.Time {
+	s := p.values.Get(key)
+	if s == "" {
+		return nil
+	}
+
+	for _, layout := range []string{time.DateOnly, time.RFC3339} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return &t
+		}
+	}
+
+	p.fail(fmt.Errorf("%s must be a date or a RFC 3339 timestamp", key))
+	return nil
+}
+
+func (p *queryParser) pagination() db.Pagination {
+	return db.Pagination{
+		Page:     p.int("page", 1, 1, 1<<20),
+		PageSize: p.int("page_size", defaultPageSize, 1, maxPageSize),
+	}
+}
+
+func (p *queryParser) fail(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
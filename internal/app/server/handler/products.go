@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+type productListingResponse struct {
+	Product entity.Product        `json:"product"`
+	Prices  []entity.ProductPrice `json:"prices"`
+}
+
+// searchProducts lists the products matching the query parameters q,
+// retailer_id, category_id, brand, store_id, min_price, max_price and
+// include_delisted, along with their current prices. The price range
+// applies to the prices at store_id, or at any store without it.
+func (h *Handler) searchProducts(w http.ResponseWriter, r *http.Request) {
+	p := newQueryParser(r)
+	filter := db.ProductFilter{
+		Pagination:      p.pagination(),
+		RetailerID:      p.string("retailer_id"),
+		CategoryID:      p.string("category_id"),
+		Brand:           p.string("brand"),
+		StoreID:         p.string("store_id"),
+		MinPrice:        p.float("min_price"),
+		MaxPrice:        p.float("max_price"),
+		IncludeDelisted: p.bool("include_delisted"),
+	}
+	if p.err != nil {
+		writeError(w, http.StatusBadRequest, p.err)
+		return
+	}
+
+	listings, total, err := h.spuc.Execute(r.Context(), p.string("q"), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	responses := make([]productListingResponse, len(listings))
+	for i, listing := range listings {
+		responses[i] = productListingResponse{
+			Product: listing.Product,
+			Prices:  nonNil(listing.Prices),
+		}
+	}
+
+	writeJSON(w, http.StatusOK, newPage(responses, filter.Pagination, total))
+}
+
+type productResponse struct {
+	Product      entity.Product                `json:"product"`
+	Prices       []entity.ProductPrice         `json:"prices"`
+	PriceHistory page[entity.PriceObservation] `json:"price_history"`
+}
+
+// getProduct returns the product along with its current prices at each
+// store and its price history, the latest observation first, filtered by the
+// query parameters store_id, from and to.
+func (h *Handler) getProduct(w http.ResponseWriter, r *http.Request) {
+	p := newQueryParser(r)
+	filter := db.PriceObservationFilter{
+		Pagination: p.pagination(),
+		StoreID:    p.string("store_id"),
+		From:       p.time("from"),
+		To:         p.time("to"),
+	}
+	if p.err != nil {
+		writeError(w, http.StatusBadRequest, p.err)
+		return
+	}
+
+	id := r.PathValue("id")
+	history, err := h.gphuc.Execute(r.Context(), id, filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if history == nil {
+		writeError(
+			w,
+			http.StatusNotFound,
+			fmt.Errorf("product %s not found", id),
+		)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, productResponse{
+		Product: history.Product,
+		Prices:  nonNil(history.Prices),
+		PriceHistory: newPage(
+			history.Observations,
+			filter.Pagination,
+			history.Total,
+		),
+	})
+}
+
+// listCategories lists the categories matching the query parameters
+// retailer_id, parent_id and root_only.
+func (h *Handler) listCategories(w http.ResponseWriter, r *http.Request) {
+	p := newQueryParser(r)
+	filter := db.CategoryFilter{
+		Pagination: p.pagination(),
+		RetailerID: p.string("retailer_id"),
+		ParentID:   p.string("parent_id"),
+		RootOnly:   p.bool("root_only"),
+	}
+	if p.err != nil {
+		writeError(w, http.StatusBadRequest, p.err)
+		return
+	}
+
+	categories, total, err := h.lcuc.Execute(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPage(categories, filter.Pagination, total))
+}
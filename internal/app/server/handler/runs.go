@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+// listRuns lists the scrape runs matching the query parameters retailer_id,
+// store_id, source and status, the latest first.
+func (h *Handler) listRuns(w http.ResponseWriter, r *http.Request) {
+	p := newQueryParser(r)
+	filter := db.ScrapeRunFilter{
+		Pagination: p.pagination(),
+		RetailerID: p.string("retailer_id"),
+		StoreID:    p.string("store_id"),
+		Source:     p.string("source"),
+		Status:     p.string("status"),
+	}
+	if p.err != nil {
+		writeError(w, http.StatusBadRequest, p.err)
+		return
+	}
+
+	runs, total, err := h.lsruc.Execute(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPage(runs, filter.Pagination, total))
+}
+
+// listErrors lists the errors matching the query parameters run_id, type
+// and include_resolved, the latest first.
+func (h *Handler) listErrors(w http.ResponseWriter, r *http.Request) {
+	p := newQueryParser(r)
+	filter := db.ErrorFilter{
+		Pagination:      p.pagination(),
+		RunID:           p.string("run_id"),
+		Type:            p.string("type"),
+		IncludeResolved: p.bool("include_resolved"),
+	}
+	if p.err != nil {
+		writeError(w, http.StatusBadRequest, p.err)
+		return
+	}
+
+	errors, total, err := h.leuc.Execute(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newPage(errors, filter.Pagination, total))
+}
@@ -0,0 +1,17 @@
+package server
+
+import (
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/server/handler"
+)
+
+type Server struct {
+	*handler.Handler
+}
+
+func Build(
+	h *handler.Handler,
+) *Server {
+	return &Server{
+		Handler: h,
+	}
+}
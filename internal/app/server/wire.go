@@ -0,0 +1,36 @@
+//go:build wireinject
+// +build wireinject
+
+package server
+
+import (
+	"github.com/google/wire"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/server/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+)
+
+func New() *Server {
+	wire.Build(
+		wire.Bind(new(validator.Validator), new(*validator.Validation)),
+		validator.New,
+
+		config.LoadConfig,
+
+		usecase.NewSearchProductsUseCase,
+		usecase.NewGetProductHistoryUseCase,
+		usecase.NewListCategoriesUseCase,
+		usecase.NewListScrapeRunsUseCase,
+		usecase.NewListErrorsUseCase,
+
+		factory.NewDB,
+
+		handler.New,
+
+		Build,
+	)
+	return &Server{}
+}
@@ -0,0 +1,31 @@
+// Code generated by Wire. DO NOT EDIT.
+
+//go:generate go run -mod=mod github.com/google/wire/cmd/wire
+//go:build !wireinject
+// +build !wireinject
+
+package server
+
+import (
+	"github.com/danielmesquitta/supermarket-scraper/internal/app/server/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+)
+
+// Injectors from wire.go:
+
+func New() *Server {
+	validation := validator.New()
+	env := config.LoadConfig(validation)
+	db := factory.NewDB(env)
+	searchProductsUseCase := usecase.NewSearchProductsUseCase(db)
+	getProductHistoryUseCase := usecase.NewGetProductHistoryUseCase(db)
+	listCategoriesUseCase := usecase.NewListCategoriesUseCase(db)
+	listScrapeRunsUseCase := usecase.NewListScrapeRunsUseCase(db)
+	listErrorsUseCase := usecase.NewListErrorsUseCase(db)
+	handlerHandler := handler.New(searchProductsUseCase, getProductHistoryUseCase, listCategoriesUseCase, listScrapeRunsUseCase, listErrorsUseCase)
+	server := Build(handlerHandler)
+	return server
+}
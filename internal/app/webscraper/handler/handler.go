@@ -2,83 +2,217 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
+	"net/url"
+	"os"
+	"path/filepath"
 	"regexp"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/selector"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/ratelimit"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi/atacadaoapi"
 	"github.com/playwright-community/playwright-go"
 )
 
 type Handler struct {
-	e    *env.Env
-	db   *sqlite.DB
-	spuc *usecase.SaveProductsUseCase
-	seuc *usecase.SaveErrorUseCase
+	e     *env.Env
+	sel   *selector.Selectors
+	gate  *ratelimit.Gate
+	db    db.DB
+	sa    *atacadaoapi.AtacadaoAPI
+	sruc  *usecase.SaveRetailerUseCase
+	scuc  *usecase.SyncCategoriesUseCase
+	ssruc *usecase.StartScrapeRunUseCase
+	fsruc *usecase.FinishScrapeRunUseCase
+	crhuc *usecase.CheckRunHealthUseCase
+	dpuc  *usecase.DelistProductsUseCase
+	apcuc *usecase.AlertPriceChangesUseCase
+	aduc  *usecase.AlertDelistingsUseCase
+	srouc *usecase.SaveRetryOutcomesUseCase
+	spuc  *usecase.SaveProductsUseCase
+	seuc  *usecase.SaveErrorUseCase
 }
 
 func New(
 	e *env.Env,
-	db *sqlite.DB,
+	sel *selector.Selectors,
+	gate *ratelimit.Gate,
+	db db.DB,
+	sa *atacadaoapi.AtacadaoAPI,
+	sruc *usecase.SaveRetailerUseCase,
+	scuc *usecase.SyncCategoriesUseCase,
+	ssruc *usecase.StartScrapeRunUseCase,
+	fsruc *usecase.FinishScrapeRunUseCase,
+	crhuc *usecase.CheckRunHealthUseCase,
+	dpuc *usecase.DelistProductsUseCase,
+	apcuc *usecase.AlertPriceChangesUseCase,
+	aduc *usecase.AlertDelistingsUseCase,
+	srouc *usecase.SaveRetryOutcomesUseCase,
 	spuc *usecase.SaveProductsUseCase,
 	seuc *usecase.SaveErrorUseCase,
 ) *Handler {
 	return &Handler{
-		e:    e,
-		db:   db,
-		spuc: spuc,
-		seuc: seuc,
+		e:     e,
+		sel:   sel,
+		gate:  gate,
+		db:    db,
+		sa:    sa,
+		sruc:  sruc,
+		scuc:  scuc,
+		ssruc: ssruc,
+		fsruc: fsruc,
+		crhuc: crhuc,
+		dpuc:  dpuc,
+		apcuc: apcuc,
+		aduc:  aduc,
+		srouc: srouc,
+		spuc:  spuc,
+		seuc:  seuc,
 	}
 }
 
-func (h *Handler) setupBrowserContext() (browserContext playwright.BrowserContext, stop func() error, err error) {
+// snapshotTimeout is how long a screenshot of a failed page may take, in
+// milliseconds.
+const snapshotTimeout = 10_000
+
+var retailer = entity.Retailer{
+	ID:   atacadaoapi.RetailerID,
+	Name: atacadaoapi.RetailerName,
+}
+
+// setupBrowserContext returns the browser context to scrape with, along with
+// a function that releases it. In cdp mode, it attaches to the Chrome started
+// by make setup. In headless mode, it launches a headless Chromium of its
+// own, which persists its profile when BROWSER_USER_DATA_DIR is set.
+func (h *Handler) setupBrowserContext() (
+	browserContext playwright.BrowserContext,
+	stop func() error,
+	err error,
+) {
 	pw, err := playwright.Run()
 	if err != nil {
 		return nil, nil, errs.New(err)
 	}
 
+	var closeBrowser func() error
+	switch h.e.BrowserMode {
+	case env.BrowserModeHeadless:
+		browserContext, closeBrowser, err = h.launchBrowserContext(pw)
+	default:
+		browserContext, err = h.connectBrowserContext(pw)
+		// The browser belongs to the user, so it is left open.
+		closeBrowser = func() error { return nil }
+	}
+	if err != nil {
+		_ = pw.Stop()
+		return nil, nil, errs.New(err)
+	}
+
+	stop = func() error {
+		return errors.Join(closeBrowser(), pw.Stop())
+	}
+
+	return browserContext, stop, nil
+}
+
+func (h *Handler) connectBrowserContext(
+	pw *playwright.Playwright,
+) (playwright.BrowserContext, error) {
 	cdpURL := fmt.Sprintf("http://localhost:%s", h.e.CDPPort)
 	browser, err := pw.Chromium.ConnectOverCDP(cdpURL)
 	if err != nil {
-		return nil, nil, errs.New(err)
+		return nil, errs.New(err)
 	}
+
 	contexts := browser.Contexts()
 	if len(contexts) == 0 {
+		return nil, errs.New(
+			fmt.Sprintf("browser at %s has no contexts", cdpURL),
+		)
+	}
+
+	return contexts[0], nil
+}
+
+func (h *Handler) launchBrowserContext(
+	pw *playwright.Playwright,
+) (playwright.BrowserContext, func() error, error) {
+	if h.e.BrowserUserDataDir != "" {
+		browserContext, err := pw.Chromium.LaunchPersistentContext(
+			h.e.BrowserUserDataDir,
+			playwright.BrowserTypeLaunchPersistentContextOptions{
+				Headless: playwright.Bool(true),
+			},
+		)
+		if err != nil {
+			return nil, nil, errs.New(err)
+		}
+
+		closeBrowser := func() error {
+			return browserContext.Close()
+		}
+
+		return browserContext, closeBrowser, nil
+	}
+
+	browser, err := pw.Chromium.Launch(playwright.BrowserTypeLaunchOptions{
+		Headless: playwright.Bool(true),
+	})
+	if err != nil {
 		return nil, nil, errs.New(err)
 	}
-	browserContext = contexts[0]
-	return browserContext, pw.Stop, nil
+
+	browserContext, err := browser.NewContext()
+	if err != nil {
+		_ = browser.Close()
+		return nil, nil, errs.New(err)
+	}
+
+	closeBrowser := func() error {
+		return browser.Close()
+	}
+
+	return browserContext, closeBrowser, nil
 }
 
+// processProductsFromBrowserContext scrapes the products of the page at url
+// in a new tab. When the page fails to be scraped, the paths of its
+// snapshots are added to metadata, unless it is nil.
 func (h *Handler) processProductsFromBrowserContext(
 	ctx context.Context,
 	browser playwright.BrowserContext,
+	runID string,
 	url string,
+	metadata map[string]any,
 ) (products []entity.Product, err error) {
 	if err = ctx.Err(); err != nil {
 		return nil, errs.New(err)
 	}
+	if err = h.waitForGate(ctx, url); err != nil {
+		return nil, errs.New(err)
+	}
 
 	var page playwright.Page
 	page, err = browser.NewPage()
 	if err != nil {
 		return nil, errs.New(err)
 	}
-
 	defer func() {
-		_ = page.Close()
-		if err == nil {
-			return
+		if err != nil && metadata != nil {
+			maps.Copy(metadata, h.snapshotPage(runID, page))
 		}
-		_ = h.seuc.Execute(
-			ctx,
-			errs.New(err, errs.ErrTypeFailedProcessingProductsPage),
-			map[string]any{"page_url": page.URL()},
-		)
+		_ = page.Close()
 	}()
 
 	if _, err = page.Goto(url); err != nil {
@@ -104,8 +238,7 @@ func (h *Handler) processProductsFromPage(
 		return nil, errs.New(err)
 	}
 
-	productsSelector := "div[data-fs-product-listing-results='true'] section[data-testid='store-product-card-content']"
-	productsLocator := page.Locator(productsSelector)
+	productsLocator := page.Locator(h.sel.Product.Listing)
 
 	err = productsLocator.First().WaitFor(playwright.LocatorWaitForOptions{
 		State: playwright.WaitForSelectorStateVisible,
@@ -124,8 +257,7 @@ func (h *Handler) processProductsFromPage(
 	}
 
 	for _, productLocator := range allProductsLocator {
-		productNameSelector := "h3"
-		productNameLocator := productLocator.Locator(productNameSelector)
+		productNameLocator := productLocator.Locator(h.sel.Product.Name)
 
 		var productName string
 		productName, err = productNameLocator.InnerText()
@@ -133,10 +265,7 @@ func (h *Handler) processProductsFromPage(
 			return nil, errs.New(err)
 		}
 
-		productBulkPriceSelector := ".text-lg.text-neutral-500.font-bold"
-		productBulkPriceLocator := productLocator.Locator(
-			productBulkPriceSelector,
-		)
+		productBulkPriceLocator := productLocator.Locator(h.sel.Product.Price)
 
 		var productBulkPriceStr string
 		productBulkPriceStr, err = productBulkPriceLocator.InnerText()
@@ -150,45 +279,288 @@ func (h *Handler) processProductsFromPage(
 			return nil, errs.New(err)
 		}
 
-		productPriceSelector := ".flex.items-center.gap-1"
-		productPriceLocator := productLocator.Locator(productPriceSelector)
+		var productPrice float64
+		var productPriceStr string
+		productPriceStr, err = optionalInnerText(
+			productLocator,
+			h.sel.Product.WholesalePrice,
+		)
+		if err != nil {
+			return nil, errs.New(err)
+		}
+		if productPriceStr != "" {
+			productPrice, err = parsePrice(productPriceStr)
+			if err != nil {
+				return nil, errs.New(err)
+			}
+		}
+
+		product := entity.Product{
+			RetailerID: retailer.ID,
+			Name:       productName,
+			Price:      productBulkPrice,
+		}
 
-		var productPriceLocatorCount int
-		productPriceLocatorCount, err = productPriceLocator.Count()
+		var productCode string
+		productCode, err = h.productCode(productLocator)
 		if err != nil {
 			return nil, errs.New(err)
 		}
+		if productCode != "" {
+			product.Code = &productCode
+		}
 
-		var productPrice float64
-		if productPriceLocatorCount > 0 {
-			var productPriceStr string
-			productPriceStr, err = productPriceLocator.InnerText()
+		// Products sold in bulk show a second, cheaper price, only charged
+		// from a minimum quantity ("a partir de X unidades").
+		if productPrice > 0 && productPrice != productBulkPrice {
+			unitPrice := max(productBulkPrice, productPrice)
+			wholesalePrice := min(productBulkPrice, productPrice)
+			product.Price = unitPrice
+			product.WholesalePrice = &wholesalePrice
+
+			var productText string
+			productText, err = productLocator.InnerText()
 			if err != nil {
 				return nil, errs.New(err)
 			}
-			productPrice, err = parsePrice(productPriceStr)
+			product.WholesaleMinQuantity = parseMinQuantity(productText)
+		}
+
+		listPrice := product.Price
+		var productListPriceStr string
+		productListPriceStr, err = optionalInnerText(
+			productLocator,
+			h.sel.Product.ListPrice,
+		)
+		if err != nil {
+			return nil, errs.New(err)
+		}
+		if productListPriceStr != "" {
+			listPrice, err = parsePrice(productListPriceStr)
 			if err != nil {
 				return nil, errs.New(err)
 			}
 		}
 
-		actualPrice := max(productBulkPrice, productPrice)
+		product.ListPrice = &listPrice
+		if product.Price < listPrice {
+			promoPrice := product.Price
+			product.PromoPrice = &promoPrice
+		}
 
-		products = append(products, entity.Product{
-			Name:  productName,
-			Price: actualPrice,
-		})
+		products = append(products, product)
 	}
 
 	return products, nil
 }
 
+// waitForGate spends a page load of the request budget, then waits for the
+// turn of the host of the URL.
+func (h *Handler) waitForGate(ctx context.Context, rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if err := h.gate.Wait(ctx, u.Host); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// snapshotPage saves the HTML and a screenshot of a page that failed to be
+// scraped into the directory of the run under ARTIFACTS_DIR, and returns
+// their paths as error metadata. Snapshots are best effort, a failure to
+// take one is only logged.
+func (h *Handler) snapshotPage(
+	runID string,
+	page playwright.Page,
+) map[string]any {
+	if h.e.ArtifactsDir == "" || page.IsClosed() {
+		return nil
+	}
+
+	dir := filepath.Join(h.e.ArtifactsDir, runID)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		slog.Warn("failed to snapshot page", "url", page.URL(), "err", err)
+		return nil
+	}
+
+	name := fmt.Sprintf(
+		"%d-%s",
+		time.Now().UnixNano(),
+		artifactName(page.URL()),
+	)
+	metadata := map[string]any{}
+
+	htmlPath := filepath.Join(dir, name+".html")
+	html, err := page.Content()
+	if err == nil {
+		err = os.WriteFile(htmlPath, []byte(html), 0o644)
+	}
+	if err != nil {
+		slog.Warn("failed to save page HTML", "url", page.URL(), "err", err)
+	} else {
+		metadata["html_path"] = htmlPath
+	}
+
+	screenshotPath := filepath.Join(dir, name+".png")
+	_, err = page.Screenshot(playwright.PageScreenshotOptions{
+		Path:     playwright.String(screenshotPath),
+		FullPage: playwright.Bool(true),
+		Timeout:  playwright.Float(snapshotTimeout),
+	})
+	if err != nil {
+		slog.Warn(
+			"failed to save page screenshot",
+			"url", page.URL(),
+			"err", err,
+		)
+	} else {
+		metadata["screenshot_path"] = screenshotPath
+	}
+
+	return metadata
+}
+
+var artifactNameRegexp = regexp.MustCompile(`[^a-zA-Z0-9]+`)
+
+// artifactName turns the URL of a page into a file name, e.g.
+// bebidas-page-2 for https://www.atacadao.com.br/bebidas?page=2.
+func artifactName(rawURL string) string {
+	name := rawURL
+	if u, err := url.Parse(rawURL); err == nil {
+		name = u.Path + "-" + u.RawQuery
+	}
+
+	name = strings.Trim(artifactNameRegexp.ReplaceAllString(name, "-"), "-")
+	if len(name) > 100 {
+		name = name[:100]
+	}
+	if name == "" {
+		name = "page"
+	}
+
+	return name
+}
+
+// productCode reads the code of the product from its card, which is empty
+// when the selectors have no code selector or the card has no code.
+func (h *Handler) productCode(
+	productLocator playwright.Locator,
+) (string, error) {
+	if h.sel.Product.CodeAttribute == "" {
+		code, err := optionalInnerText(productLocator, h.sel.Product.Code)
+		if err != nil {
+			return "", errs.New(err)
+		}
+		return strings.TrimSpace(code), nil
+	}
+
+	codeLocator := productLocator.Locator(h.sel.Product.Code).First()
+	count, err := codeLocator.Count()
+	if err != nil {
+		return "", errs.New(err)
+	}
+	if count == 0 {
+		return "", nil
+	}
+
+	code, err := codeLocator.GetAttribute(h.sel.Product.CodeAttribute)
+	if err != nil {
+		return "", errs.New(err)
+	}
+
+	return strings.TrimSpace(code), nil
+}
+
+// optionalInnerText returns the text of the first element matching the
+// selector inside the locator, which is empty when the selector is empty or
+// nothing matches it.
+func optionalInnerText(
+	locator playwright.Locator,
+	selector string,
+) (string, error) {
+	if selector == "" {
+		return "", nil
+	}
+
+	elementLocator := locator.Locator(selector).First()
+	count, err := elementLocator.Count()
+	if err != nil {
+		return "", errs.New(err)
+	}
+	if count == 0 {
+		return "", nil
+	}
+
+	text, err := elementLocator.InnerText()
+	if err != nil {
+		return "", errs.New(err)
+	}
+
+	return text, nil
+}
+
+// saveProducts saves the products scraped from a page of the category.
+func (h *Handler) saveProducts(
+	ctx context.Context,
+	run *usecase.RunTracker,
+	category string,
+	products []entity.Product,
+) error {
+	categoryID := run.CategoryID(category)
+	for i := range products {
+		products[i].CategoryID = categoryID
+	}
+
+	observations, err := h.spuc.Execute(ctx, run, products)
+	if err != nil {
+		return errs.New(err)
+	}
+	run.AddProducts(category, products)
+
+	// A failed alert does not fail the page, whose products are saved.
+	if err := h.apcuc.Execute(ctx, run, observations); err != nil {
+		_ = h.seuc.Execute(
+			ctx,
+			run.ID,
+			errs.New(err, errs.ErrTypeFailedSendingAlerts),
+			map[string]any{
+				"retailer_id": run.RetailerID,
+				"category":    category,
+			},
+		)
+	}
+
+	return nil
+}
+
 func parseInt(s string) (int, error) {
 	re := regexp.MustCompile("[^0-9]+")
 	numStr := re.ReplaceAllString(s, "")
 	return strconv.Atoi(numStr)
 }
 
+var minQuantityRegexp = regexp.MustCompile(`(?i)a partir de (\d+)`)
+
+// parseMinQuantity parses the minimum quantity of a wholesale price from
+// a text like "a partir de 6 unidades".
+func parseMinQuantity(s string) *int {
+	matches := minQuantityRegexp.FindStringSubmatch(s)
+	if matches == nil {
+		return nil
+	}
+
+	quantity, err := strconv.Atoi(matches[1])
+	if err != nil {
+		return nil
+	}
+
+	return &quantity
+}
+
 func parsePrice(s string) (float64, error) {
 	centsInt, err := parseInt(s)
 	if err != nil {
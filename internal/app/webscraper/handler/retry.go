@@ -3,16 +3,18 @@ package handler
 import (
 	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"sync"
 
 	"golang.org/x/sync/errgroup"
 
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/playwright-community/playwright-go"
 )
 
-const retryPagesLimit = 10
-
-func (h *Handler) Retry(ctx context.Context) error {
+func (h *Handler) Retry(ctx context.Context) (err error) {
 	dbErrors, err := h.db.ListErrorsByType(
 		ctx,
 		errs.ErrTypeFailedProcessingProductsPage,
@@ -25,13 +27,34 @@ func (h *Handler) Retry(ctx context.Context) error {
 		return nil
 	}
 
+	categories, err := h.db.ListCategories(ctx, retailer.ID)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	var run *usecase.RunTracker
+	run, err = h.ssruc.Execute(ctx, usecase.StartScrapeRunOptions{
+		RetailerID: retailer.ID,
+		Source:     entity.SourceWeb,
+		Kind:       entity.RunKindRetry,
+	})
+	if err != nil {
+		return errs.New(err)
+	}
+	run.SetCategories(categories)
+
+	mu := sync.Mutex{}
+	outcomes := []usecase.RetryOutcome{}
+
 	defer func() {
-		ids := []string{}
-		for _, dbError := range dbErrors {
-			ids = append(ids, dbError.ID)
+		// The outcomes must be stored and the run closed even if the retry
+		// was cancelled.
+		ctx := context.WithoutCancel(ctx)
+		if saveErr := h.srouc.Execute(ctx, outcomes); saveErr != nil {
+			err = errors.Join(err, saveErr)
 		}
-		if err := h.db.DeleteErrors(ctx, ids); err != nil {
-			log.Fatalf("failed to delete errors: %v", err)
+		if finishErr := h.fsruc.Execute(ctx, run, err); finishErr != nil {
+			err = errors.Join(err, finishErr)
 		}
 	}()
 
@@ -42,35 +65,20 @@ func (h *Handler) Retry(ctx context.Context) error {
 	defer func() { _ = stop() }()
 
 	g := errgroup.Group{}
-	g.SetLimit(retryPagesLimit)
+	g.SetLimit(h.e.WebRetryConcurrency)
 	for _, dbError := range dbErrors {
 		g.Go(func() error {
-			metadata := map[string]string{}
-			if err := json.Unmarshal([]byte(dbError.Metadata), &metadata); err != nil {
-				return errs.New(err)
+			err := h.retryError(ctx, run, browser, dbError)
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return errs.New(ctxErr)
 			}
 
-			url := metadata["page_url"]
-			if url == "" {
-				return errs.New("invalid metadata")
-			}
-
-			products, err := h.processProductsFromBrowserContext(
-				ctx,
-				browser,
-				url,
-			)
-			if err != nil {
-				return errs.New(err)
-			}
-
-			if len(products) == 0 {
-				return nil
-			}
-
-			if err = h.spuc.Execute(ctx, products); err != nil {
-				return errs.New(err)
-			}
+			mu.Lock()
+			outcomes = append(outcomes, usecase.RetryOutcome{
+				Error: dbError,
+				Err:   err,
+			})
+			mu.Unlock()
 
 			return nil
 		})
@@ -82,3 +90,45 @@ func (h *Handler) Retry(ctx context.Context) error {
 
 	return nil
 }
+
+func (h *Handler) retryError(
+	ctx context.Context,
+	run *usecase.RunTracker,
+	browser playwright.BrowserContext,
+	dbError entity.Error,
+) error {
+	metadata := map[string]string{}
+	if err := json.Unmarshal([]byte(dbError.Metadata), &metadata); err != nil {
+		return errs.New(err)
+	}
+
+	url := metadata["page_url"]
+	if url == "" {
+		return errs.New("invalid metadata")
+	}
+	category := metadata["category"]
+
+	// The error being retried already has snapshots of the page.
+	products, err := h.processProductsFromBrowserContext(
+		ctx,
+		browser,
+		run.ID,
+		url,
+		nil,
+	)
+	if err != nil {
+		run.AddError(category)
+		return errs.New(err)
+	}
+
+	if len(products) == 0 {
+		return nil
+	}
+
+	if err := h.saveProducts(ctx, run, category, products); err != nil {
+		run.AddError(category)
+		return errs.New(err)
+	}
+
+	return nil
+}
@@ -2,37 +2,80 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
+	"maps"
 	"math"
+	"regexp"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
 	"github.com/playwright-community/playwright-go"
 )
 
-var categories = []string{
-	"bebidas",
-	"mercearia",
-	"limpeza",
-	"higiene-e-perfumaria",
-	"padaria-e-matinais",
-	"papelaria",
-	"pet-shop",
-	"automotivo",
-	"eletronicos-e-eletroportateis",
-	"vestuario",
-	"utilidades-domesticas",
-	"descartaveis-e-embalagens",
-	"esporte-e-lazer",
-}
+// totalCountTimeout is how long a category page may take to show how many
+// products it has.
+const totalCountTimeout = 30 * time.Second
+
+var nonZeroRegexp = regexp.MustCompile(`[1-9]`)
+
+func (h *Handler) Run(ctx context.Context) (err error) {
+	if err = h.sruc.Execute(ctx, retailer); err != nil {
+		return errs.New(err)
+	}
+
+	var run *usecase.RunTracker
+	run, err = h.ssruc.Execute(ctx, usecase.StartScrapeRunOptions{
+		RetailerID: retailer.ID,
+		Source:     entity.SourceWeb,
+		Kind:       entity.RunKindCrawl,
+	})
+	if err != nil {
+		return errs.New(err)
+	}
+
+	defer func() {
+		// The run must be closed even if the scrape was cancelled.
+		ctx := context.WithoutCancel(ctx)
+		if finishErr := h.fsruc.Execute(ctx, run, err); finishErr != nil {
+			err = errors.Join(err, finishErr)
+		}
+		if healthErr := h.crhuc.Execute(ctx, run); healthErr != nil {
+			err = errors.Join(err, healthErr)
+		}
+		delisted, delistErr := h.dpuc.Execute(ctx, run)
+		if delistErr != nil {
+			err = errors.Join(err, delistErr)
+		}
+		if alertErr := h.aduc.Execute(ctx, run, delisted); alertErr != nil {
+			_ = h.seuc.Execute(
+				ctx,
+				run.ID,
+				errs.New(alertErr, errs.ErrTypeFailedSendingAlerts),
+				map[string]any{"retailer_id": retailer.ID},
+			)
+		}
+	}()
 
-const categoryPagesLimit = 2
-const productPagesLimit = 5
+	var categories []entity.Category
+	categories, err = h.scuc.Execute(ctx, h.sa)
+	if err != nil {
+		run.AddError("")
+		_ = h.seuc.Execute(
+			ctx,
+			run.ID,
+			errs.New(err, errs.ErrTypeFailedListingCategories),
+			map[string]any{"retailer_id": retailer.ID},
+		)
+		return errs.New(err)
+	}
+	run.SetCategories(categories)
 
-func (h *Handler) Run(ctx context.Context) error {
 	browser, stop, err := h.setupBrowserContext()
 	if err != nil {
 		return errs.New(err)
@@ -40,10 +83,16 @@ func (h *Handler) Run(ctx context.Context) error {
 	defer func() { _ = stop() }()
 
 	g := errgroup.Group{}
-	g.SetLimit(categoryPagesLimit)
+	g.SetLimit(h.e.WebCategoryConcurrency)
 	for _, category := range categories {
+		// Subcategory listings are part of their top-level category's.
+		if category.ParentID != nil {
+			continue
+		}
+
 		g.Go(func() error {
-			if err := h.processCategory(ctx, browser, category); err != nil {
+			err := h.processCategory(ctx, run, browser, category.Path)
+			if err != nil {
 				return errs.New(err)
 			}
 
@@ -58,74 +107,53 @@ func (h *Handler) Run(ctx context.Context) error {
 	return nil
 }
 
+// processCategory scrapes every page of the category. A page that fails is
+// recorded, snapshots included, and the run goes on without it, ending
+// partial.
 func (h *Handler) processCategory(
 	ctx context.Context,
+	run *usecase.RunTracker,
 	browser playwright.BrowserContext,
 	category string,
-) (err error) {
-	var page playwright.Page
-	page, err = browser.NewPage()
-	if err != nil {
+) error {
+	url := h.sel.URL(category, 1)
+
+	// A category the budget cannot afford is not a failure of its page.
+	if err := h.waitForGate(ctx, url); err != nil {
 		return errs.New(err)
 	}
 
-	defer func() {
-		_ = page.Close()
-		if err == nil {
-			return
+	metadata := map[string]any{"page_url": url, "category": category}
+	totalProductsCount, products, err := h.processCategoryPage(
+		ctx,
+		browser,
+		run.ID,
+		url,
+		metadata,
+	)
+	if err != nil {
+		if ctx.Err() != nil {
+			return errs.New(err)
 		}
-
+		run.AddError(category)
 		_ = h.seuc.Execute(
 			ctx,
+			run.ID,
 			errs.New(err, errs.ErrTypeFailedProcessingCategoryPage),
-			map[string]any{"page_url": page.URL()},
+			metadata,
 		)
-	}()
-
-	if err = ctx.Err(); err != nil {
-		return errs.New(err)
-	}
-
-	if _, err = page.Goto(fmt.Sprintf("https://www.atacadao.com.br/%s", category)); err != nil {
-		return errs.New(err)
-	}
-	if err = page.WaitForLoadState(); err != nil {
-		return errs.New(err)
-	}
-
-	var totalProductsCount int
-	for totalProductsCount == 0 {
-		time.Sleep(time.Second)
-
-		totalProductsCountSelector := "h2[data-testid='total-product-count']"
-		totalProductsCountLocator := page.Locator(totalProductsCountSelector).
-			First()
-
-		var totalProductsCountStr string
-		totalProductsCountStr, err = totalProductsCountLocator.InnerText()
-		if err != nil {
-			return errs.New(err)
-		}
-
-		totalProductsCount, err = parseInt(totalProductsCountStr)
-		if err != nil {
-			return errs.New(err)
-		}
-	}
-
-	var products []entity.Product
-	products, err = h.processProductsFromPage(ctx, page)
-	if err != nil {
-		return errs.New(err)
+		return nil
 	}
-	if err = page.Close(); err != nil {
-		return errs.New(err)
+	if totalProductsCount == 0 {
+		slog.Info("category has no products", "category", category)
+		run.MarkEmpty(category)
+		return nil
 	}
 	if len(products) == 0 {
 		return nil
 	}
 
-	if err = h.spuc.Execute(ctx, products); err != nil {
+	if err := h.saveProducts(ctx, run, category, products); err != nil {
 		return errs.New(err)
 	}
 
@@ -133,29 +161,40 @@ func (h *Handler) processCategory(
 	pagesCount := math.Ceil(float64(totalProductsCount) / float64(pageSize))
 
 	g := errgroup.Group{}
-	g.SetLimit(productPagesLimit)
+	g.SetLimit(h.e.WebPageConcurrency)
 	for pageCount := 2; pageCount <= int(pagesCount); pageCount++ {
 		g.Go(func() error {
-			url := fmt.Sprintf(
-				"https://www.atacadao.com.br/%s?page=%d",
-				category,
-				pageCount,
-			)
+			url := h.sel.URL(category, pageCount)
+			metadata := map[string]any{"page_url": url, "category": category}
 
-			products, err = h.processProductsFromBrowserContext(
+			products, err := h.processProductsFromBrowserContext(
 				ctx,
 				browser,
+				run.ID,
 				url,
+				metadata,
 			)
 			if err != nil {
-				return errs.New(err)
+				// Pages of a cancelled run, or that the budget cannot afford,
+				// are not failures of theirs.
+				if ctx.Err() != nil || h.gate.Exhausted() {
+					return errs.New(err)
+				}
+				run.AddError(category)
+				_ = h.seuc.Execute(
+					ctx,
+					run.ID,
+					errs.New(err, errs.ErrTypeFailedProcessingProductsPage),
+					metadata,
+				)
+				return nil
 			}
 
 			if len(products) == 0 {
 				return nil
 			}
 
-			if err = h.spuc.Execute(ctx, products); err != nil {
+			if err := h.saveProducts(ctx, run, category, products); err != nil {
 				return errs.New(err)
 			}
 
@@ -169,3 +208,103 @@ func (h *Handler) processCategory(
 
 	return nil
 }
+
+// processCategoryPage scrapes the first page of a category in a new tab,
+// returning how many products the category has along with the products of
+// the page. When the page fails to be scraped, the paths of its snapshots
+// are added to metadata.
+func (h *Handler) processCategoryPage(
+	ctx context.Context,
+	browser playwright.BrowserContext,
+	runID string,
+	url string,
+	metadata map[string]any,
+) (totalProductsCount int, products []entity.Product, err error) {
+	if err = ctx.Err(); err != nil {
+		return 0, nil, errs.New(err)
+	}
+
+	var page playwright.Page
+	page, err = browser.NewPage()
+	if err != nil {
+		return 0, nil, errs.New(err)
+	}
+	defer func() {
+		if err != nil {
+			maps.Copy(metadata, h.snapshotPage(runID, page))
+		}
+		_ = page.Close()
+	}()
+
+	if _, err = page.Goto(url); err != nil {
+		return 0, nil, errs.New(err)
+	}
+	if err = page.WaitForLoadState(); err != nil {
+		return 0, nil, errs.New(err)
+	}
+
+	totalProductsCount, err = h.waitForTotalCount(ctx, page)
+	if err != nil {
+		return 0, nil, errs.New(err)
+	}
+	if totalProductsCount == 0 {
+		return 0, nil, nil
+	}
+
+	products, err = h.processProductsFromPage(ctx, page)
+	if err != nil {
+		return 0, nil, errs.New(err)
+	}
+
+	return totalProductsCount, products, nil
+}
+
+// waitForTotalCount waits for the category page to show how many products
+// it has. The counter shows zero until the listing loads, so a zero is only
+// taken for an empty category once it lasted the whole timeout. The wait is
+// interrupted by closing the page when ctx is done.
+func (h *Handler) waitForTotalCount(
+	ctx context.Context,
+	page playwright.Page,
+) (int, error) {
+	stop := context.AfterFunc(ctx, func() { _ = page.Close() })
+	defer stop()
+
+	totalProductsCountLocator := page.Locator(h.sel.TotalCount).First()
+
+	err := page.Locator(h.sel.TotalCount).
+		Filter(playwright.LocatorFilterOptions{HasText: nonZeroRegexp}).
+		First().
+		WaitFor(playwright.LocatorWaitForOptions{
+			State:   playwright.WaitForSelectorStateVisible,
+			Timeout: playwright.Float(float64(totalCountTimeout.Milliseconds())),
+		})
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return 0, errs.New(ctxErr)
+	}
+	if err != nil && !errors.Is(err, playwright.ErrTimeout) {
+		return 0, errs.New(err)
+	}
+
+	count, err := totalProductsCountLocator.Count()
+	if err != nil {
+		return 0, errs.New(err)
+	}
+	if count == 0 {
+		return 0, errs.New(
+			fmt.Sprintf("total count not found after %s", totalCountTimeout),
+		)
+	}
+
+	totalProductsCountStr, err := totalProductsCountLocator.InnerText()
+	if err != nil {
+		return 0, errs.New(err)
+	}
+
+	totalProductsCount, err := parseInt(totalProductsCountStr)
+	if err != nil {
+		return 0, errs.New(err)
+	}
+
+	return totalProductsCount, nil
+}
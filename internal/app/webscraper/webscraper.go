@@ -1,7 +1,14 @@
 package webscraper
 
 import (
+	"log"
+
 	"github.com/danielmesquitta/supermarket-scraper/internal/app/webscraper/handler"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/selector"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/ratelimit"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi/atacadaoapi"
 )
 
 type WebScraper struct {
@@ -15,3 +22,24 @@ func Build(
 		Handler: h,
 	}
 }
+
+// NewSelectors loads the selectors of the pages the web scraper reads, so
+// that a broken selector is caught on startup rather than mid-run.
+func NewSelectors(
+	e *env.Env,
+	v validator.Validator,
+) *selector.Selectors {
+	s, err := selector.Load(v, e.SelectorsDir, atacadaoapi.RetailerID)
+	if err != nil {
+		log.Fatalf("failed to load selectors: %v", err)
+	}
+
+	return s
+}
+
+// NewGate returns the gate every request of the web scraper, page loads included goes through.
+func NewGate(
+	e *env.Env,
+) *ratelimit.Gate {
+	return ratelimit.NewGate(e.HostDelay, e.RequestBudget)
+}
@@ -10,7 +10,9 @@ import (
 	"github.com/danielmesquitta/supermarket-scraper/internal/config"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
 	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+	notifierfactory "github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier/factory"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi/atacadaoapi"
 )
 
 func New() *WebScraper {
@@ -19,11 +21,24 @@ func New() *WebScraper {
 		validator.New,
 
 		config.LoadConfig,
-
+		NewGate,
+		NewSelectors,
+
+		usecase.NewSaveRetailerUseCase,
+		usecase.NewSyncCategoriesUseCase,
+		usecase.NewStartScrapeRunUseCase,
+		usecase.NewFinishScrapeRunUseCase,
+		usecase.NewCheckRunHealthUseCase,
+		usecase.NewDelistProductsUseCase,
+		usecase.NewAlertPriceChangesUseCase,
+		usecase.NewAlertDelistingsUseCase,
+		usecase.NewSaveRetryOutcomesUseCase,
 		usecase.NewSaveProductsUseCase,
 		usecase.NewSaveErrorUseCase,
 
-		sqlite.New,
+		factory.NewDB,
+		notifierfactory.NewNotifier,
+		atacadaoapi.New,
 
 		handler.New,
 
@@ -11,7 +11,9 @@ import (
 	"github.com/danielmesquitta/supermarket-scraper/internal/config"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
 	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/factory"
+	factory2 "github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier/factory"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi/atacadaoapi"
 )
 
 // Injectors from wire.go:
@@ -19,10 +21,23 @@ import (
 func New() *WebScraper {
 	validation := validator.New()
 	env := config.LoadConfig(validation)
-	db := sqlite.New(env)
-	saveProductsUseCase := usecase.NewSaveProductsUseCase(db)
+	selectors := NewSelectors(env, validation)
+	gate := NewGate(env)
+	db := factory.NewDB(env)
+	atacadaoAPI := atacadaoapi.New(env, gate)
+	saveRetailerUseCase := usecase.NewSaveRetailerUseCase(db)
+	syncCategoriesUseCase := usecase.NewSyncCategoriesUseCase(db)
+	startScrapeRunUseCase := usecase.NewStartScrapeRunUseCase(db)
+	finishScrapeRunUseCase := usecase.NewFinishScrapeRunUseCase(db)
 	saveErrorUseCase := usecase.NewSaveErrorUseCase(db)
-	handlerHandler := handler.New(env, db, saveProductsUseCase, saveErrorUseCase)
+	checkRunHealthUseCase := usecase.NewCheckRunHealthUseCase(db, saveErrorUseCase)
+	delistProductsUseCase := usecase.NewDelistProductsUseCase(db)
+	notifier := factory2.NewNotifier(env)
+	alertPriceChangesUseCase := usecase.NewAlertPriceChangesUseCase(db, notifier)
+	alertDelistingsUseCase := usecase.NewAlertDelistingsUseCase(db, notifier)
+	saveRetryOutcomesUseCase := usecase.NewSaveRetryOutcomesUseCase(env, db)
+	saveProductsUseCase := usecase.NewSaveProductsUseCase(db)
+	handlerHandler := handler.New(env, selectors, gate, db, atacadaoAPI, saveRetailerUseCase, syncCategoriesUseCase, startScrapeRunUseCase, finishScrapeRunUseCase, checkRunHealthUseCase, delistProductsUseCase, alertPriceChangesUseCase, alertDelistingsUseCase, saveRetryOutcomesUseCase, saveProductsUseCase, saveErrorUseCase)
 	webScraper := Build(handlerHandler)
 	return webScraper
 }
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	root "github.com/danielmesquitta/supermarket-scraper"
 	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
@@ -23,15 +24,60 @@ const (
 	EnvironmentTest        Environment = "test"
 )
 
+type DBDriver string
+
+const (
+	DBDriverSQLite   DBDriver = "sqlite"
+	DBDriverPostgres DBDriver = "postgres"
+)
+
+type BrowserMode string
+
+const (
+	BrowserModeCDP      BrowserMode = "cdp"
+	BrowserModeHeadless BrowserMode = "headless"
+)
+
+type Notifier string
+
+const (
+	NotifierStdout  Notifier = "stdout"
+	NotifierWebhook Notifier = "webhook"
+)
+
 type Env struct {
 	v validator.Validator
 
-	Environment        Environment `mapstructure:"ENVIRONMENT"           validate:"required,oneof=development production staging test"`
-	ChromePath         string      `mapstructure:"CHROME_PATH"           validate:"required"`
-	CDPPort            string      `mapstructure:"CDP_PORT"              validate:"required"`
-	SQLiteDBPath       string      `mapstructure:"SQLITE_DB_PATH"        validate:"required"`
-	PostgresDBURL      string      `mapstructure:"POSTGRES_DB_URL"       validate:"required"`
-	AtacadaoAPIBaseURL string      `mapstructure:"ATACADAO_API_BASE_URL" validate:"required"`
+	Environment            Environment   `mapstructure:"ENVIRONMENT"              validate:"required,oneof=development production staging test"`
+	BrowserMode            BrowserMode   `mapstructure:"BROWSER_MODE"             validate:"omitempty,oneof=cdp headless"`
+	BrowserUserDataDir     string        `mapstructure:"BROWSER_USER_DATA_DIR"`
+	ChromePath             string        `mapstructure:"CHROME_PATH"              validate:"required_unless=BrowserMode headless"`
+	CDPPort                string        `mapstructure:"CDP_PORT"                 validate:"required_unless=BrowserMode headless"`
+	SelectorsDir           string        `mapstructure:"SELECTORS_DIR"`
+	ArtifactsDir           string        `mapstructure:"ARTIFACTS_DIR"`
+	DBDriver               DBDriver      `mapstructure:"DB_DRIVER"                validate:"omitempty,oneof=sqlite postgres"`
+	SQLiteDBPath           string        `mapstructure:"SQLITE_DB_PATH"           validate:"required_unless=DBDriver postgres"`
+	PostgresDBURL          string        `mapstructure:"POSTGRES_DB_URL"          validate:"required_if=DBDriver postgres"`
+	AtacadaoAPIBaseURL     string        `mapstructure:"ATACADAO_API_BASE_URL"    validate:"required"`
+	AtacadaoStores         []string      `mapstructure:"ATACADAO_STORES"          validate:"omitempty,dive,required"`
+	AtacadaoSalesChannel   string        `mapstructure:"ATACADAO_SALES_CHANNEL"   validate:"omitempty,numeric"`
+	RetryMaxAttempts       int           `mapstructure:"RETRY_MAX_ATTEMPTS"       validate:"omitempty,min=1"`
+	HTTPTimeout            time.Duration `mapstructure:"HTTP_TIMEOUT"             validate:"omitempty,min=1s"`
+	HTTPRetryCount         *int          `mapstructure:"HTTP_RETRY_COUNT"         validate:"omitempty,min=0,max=10"`
+	HTTPRetryWaitTime      time.Duration `mapstructure:"HTTP_RETRY_WAIT_TIME"     validate:"omitempty,min=1ms"`
+	HTTPRetryMaxWaitTime   time.Duration `mapstructure:"HTTP_RETRY_MAX_WAIT_TIME" validate:"omitempty,gtefield=HTTPRetryWaitTime"`
+	HTTPRequestsPerSecond  float64       `mapstructure:"HTTP_REQUESTS_PER_SECOND" validate:"omitempty,gt=0"`
+	HostDelay              time.Duration `mapstructure:"HOST_DELAY"               validate:"omitempty,min=1ms,max=1m"`
+	RequestBudget          int           `mapstructure:"REQUEST_BUDGET"           validate:"omitempty,min=1"`
+	APICategoryConcurrency int           `mapstructure:"API_CATEGORY_CONCURRENCY" validate:"omitempty,min=1,max=50"`
+	APIPageConcurrency     int           `mapstructure:"API_PAGE_CONCURRENCY"     validate:"omitempty,min=1,max=50"`
+	APIPageSize            int           `mapstructure:"API_PAGE_SIZE"            validate:"omitempty,min=1,max=100"`
+	APIRetryConcurrency    int           `mapstructure:"API_RETRY_CONCURRENCY"    validate:"omitempty,min=1,max=50"`
+	WebCategoryConcurrency int           `mapstructure:"WEB_CATEGORY_CONCURRENCY" validate:"omitempty,min=1,max=20"`
+	WebPageConcurrency     int           `mapstructure:"WEB_PAGE_CONCURRENCY"     validate:"omitempty,min=1,max=20"`
+	WebRetryConcurrency    int           `mapstructure:"WEB_RETRY_CONCURRENCY"    validate:"omitempty,min=1,max=50"`
+	Notifier               Notifier      `mapstructure:"NOTIFIER"                 validate:"omitempty,oneof=stdout webhook"`
+	AlertWebhookURL        string        `mapstructure:"ALERT_WEBHOOK_URL"        validate:"required_if=Notifier webhook,omitempty,url"`
 }
 
 func New(v validator.Validator) *Env {
@@ -52,15 +98,28 @@ func (e *Env) loadEnv() error {
 		return fmt.Errorf("failed to get environment file: %w", err)
 	}
 
-	viper.SetConfigType("env")
+	return e.load(envFile)
+}
+
+func (e *Env) load(envFile []byte) error {
+	v := viper.New()
+	v.SetConfigType("env")
 
-	if err := viper.ReadConfig(bytes.NewBuffer(envFile)); err != nil {
+	if err := v.ReadConfig(bytes.NewBuffer(envFile)); err != nil {
 		return fmt.Errorf("failed to read environment file: %w", err)
 	}
 
-	viper.AutomaticEnv()
+	// AutomaticEnv only overrides the keys the environment file has, so the
+	// rest are bound one by one, e.g. a HOST_DELAY set by --host-delay when
+	// the file predates it.
+	v.AutomaticEnv()
+	for _, key := range keys() {
+		if err := v.BindEnv(key); err != nil {
+			return fmt.Errorf("failed to bind environment variable: %w", err)
+		}
+	}
 
-	if err := viper.Unmarshal(&e); err != nil {
+	if err := v.Unmarshal(&e); err != nil {
 		return fmt.Errorf("failed to unmarshal environment file: %w", err)
 	}
 
@@ -97,6 +156,62 @@ func (e *Env) validate() error {
 	if e.Environment == "" {
 		e.Environment = EnvironmentDevelopment
 	}
+	if e.BrowserMode == "" {
+		e.BrowserMode = BrowserModeCDP
+	}
+	if e.DBDriver == "" {
+		e.DBDriver = DBDriverSQLite
+	}
+	if len(e.AtacadaoStores) == 0 {
+		e.AtacadaoStores = []string{"atacadaobr30"}
+	}
+	if e.AtacadaoSalesChannel == "" {
+		e.AtacadaoSalesChannel = "1"
+	}
+	if e.RetryMaxAttempts == 0 {
+		e.RetryMaxAttempts = 5
+	}
+	if e.HTTPTimeout == 0 {
+		e.HTTPTimeout = 30 * time.Second
+	}
+	// Zero retries is a valid setting, so only a missing count defaults.
+	if e.HTTPRetryCount == nil {
+		retryCount := 3
+		e.HTTPRetryCount = &retryCount
+	}
+	if e.HTTPRetryWaitTime == 0 {
+		e.HTTPRetryWaitTime = time.Second
+	}
+	if e.HTTPRetryMaxWaitTime == 0 {
+		e.HTTPRetryMaxWaitTime = 30 * time.Second
+	}
+	if e.HTTPRequestsPerSecond == 0 {
+		e.HTTPRequestsPerSecond = 5
+	}
+	if e.APICategoryConcurrency == 0 {
+		e.APICategoryConcurrency = 5
+	}
+	if e.APIPageConcurrency == 0 {
+		e.APIPageConcurrency = 10
+	}
+	if e.APIPageSize == 0 {
+		e.APIPageSize = 100
+	}
+	if e.APIRetryConcurrency == 0 {
+		e.APIRetryConcurrency = 10
+	}
+	if e.WebCategoryConcurrency == 0 {
+		e.WebCategoryConcurrency = 2
+	}
+	if e.WebPageConcurrency == 0 {
+		e.WebPageConcurrency = 5
+	}
+	if e.WebRetryConcurrency == 0 {
+		e.WebRetryConcurrency = 10
+	}
+	if e.Notifier == "" {
+		e.Notifier = NotifierStdout
+	}
 
 	return nil
 }
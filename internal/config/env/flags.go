@@ -0,0 +1,43 @@
+package env
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"reflect"
+	"strings"
+)
+
+// OverrideFromFlags makes the flags set on the command line take precedence
+// over the environment variables they are named after, e.g. --host-delay
+// over HOST_DELAY, so that they are validated along with the rest of the
+// environment. It must be called after the flags are parsed and before the
+// environment is loaded.
+func OverrideFromFlags(fs *flag.FlagSet) error {
+	envKeys := map[string]bool{}
+	for _, key := range keys() {
+		envKeys[key] = true
+	}
+
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		key := strings.ToUpper(strings.ReplaceAll(f.Name, "-", "_"))
+		if envKeys[key] {
+			err = errors.Join(err, os.Setenv(key, f.Value.String()))
+		}
+	})
+
+	return err
+}
+
+// keys returns the environment variables of Env.
+func keys() []string {
+	keys := []string{}
+	t := reflect.TypeFor[Env]()
+	for i := range t.NumField() {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,61 @@
+package env
+
+import (
+	"flag"
+	"testing"
+	"time"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
+)
+
+func TestOverrideFromFlags(t *testing.T) {
+	// The environment file predates the settings the flags override.
+	envFile := []byte(`ENVIRONMENT=test
+BROWSER_MODE=headless
+SQLITE_DB_PATH=./sql/sqlite.db
+ATACADAO_API_BASE_URL=https://www.atacadao.com.br/api/graphql
+HTTP_RETRY_COUNT=3
+`)
+
+	// The variables the flags set are restored after the test.
+	for _, key := range []string{"HOST_DELAY", "API_PAGE_SIZE", "HTTP_RETRY_COUNT"} {
+		t.Setenv(key, "")
+	}
+
+	fs := flag.NewFlagSet("apiscraper", flag.ContinueOnError)
+	fs.Duration("host-delay", 0, "")
+	fs.Int("api-page-size", 0, "")
+	fs.Int("http-retry-count", 0, "")
+	fs.Int("request-budget", 0, "")
+	err := fs.Parse([]string{
+		"--host-delay=750ms",
+		"--api-page-size=50",
+		"--http-retry-count=0",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := OverrideFromFlags(fs); err != nil {
+		t.Fatal(err)
+	}
+
+	e := &Env{v: validator.New()}
+	if err := e.load(envFile); err != nil {
+		t.Fatal(err)
+	}
+
+	if e.HostDelay != 750*time.Millisecond {
+		t.Errorf("got HostDelay %v, want 750ms", e.HostDelay)
+	}
+	if e.APIPageSize != 50 {
+		t.Errorf("got APIPageSize %d, want 50", e.APIPageSize)
+	}
+	if e.HTTPRetryCount == nil || *e.HTTPRetryCount != 0 {
+		t.Errorf("got HTTPRetryCount %v, want 0", e.HTTPRetryCount)
+	}
+	// Flags left unset keep the default.
+	if e.RequestBudget != 0 {
+		t.Errorf("got RequestBudget %d, want 0", e.RequestBudget)
+	}
+}
@@ -0,0 +1,115 @@
+package selector
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/viper"
+
+	root "github.com/danielmesquitta/supermarket-scraper"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/validator"
+)
+
+// Selectors tell the web scraper where to find the products on the pages of
+// a retailer.
+type Selectors struct {
+	// CategoryURL and PageURL are the URLs of the first and of the following
+	// pages of a category, with {category} and {page} placeholders.
+	CategoryURL string           `mapstructure:"category_url" validate:"required,contains={category}"`
+	PageURL     string           `mapstructure:"page_url"     validate:"required,contains={category},contains={page}"`
+	TotalCount  string           `mapstructure:"total_count"  validate:"required"`
+	Product     ProductSelectors `mapstructure:"product"`
+}
+
+// ProductSelectors locate the product cards of a listing, and the data of
+// a product inside its card.
+type ProductSelectors struct {
+	Listing        string `mapstructure:"listing"         validate:"required"`
+	Name           string `mapstructure:"name"            validate:"required"`
+	Price          string `mapstructure:"price"           validate:"required"`
+	WholesalePrice string `mapstructure:"wholesale_price"`
+	ListPrice      string `mapstructure:"list_price"`
+	Code           string `mapstructure:"code"`
+	CodeAttribute  string `mapstructure:"code_attribute"  validate:"excluded_without=Code"`
+}
+
+// URL returns the URL of the page of the category, pages counting from 1.
+func (s *Selectors) URL(category string, page int) string {
+	url := s.CategoryURL
+	if page > 1 {
+		url = s.PageURL
+	}
+
+	return strings.NewReplacer(
+		"{category}", category,
+		"{page}", strconv.Itoa(page),
+	).Replace(url)
+}
+
+var exts = []string{"yaml", "yml", "json"}
+
+// Load loads the selectors of the retailer from its YAML or JSON file in
+// dir, e.g. atacadao.yaml, falling back to the defaults embedded from the
+// selectors directory when dir is empty or has no file for the retailer.
+func Load(
+	v validator.Validator,
+	dir string,
+	retailerID string,
+) (*Selectors, error) {
+	file, ext, err := readFile(dir, retailerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read selectors file: %w", err)
+	}
+
+	vp := viper.New()
+	vp.SetConfigType(ext)
+	if err := vp.ReadConfig(bytes.NewBuffer(file)); err != nil {
+		return nil, fmt.Errorf("failed to parse selectors file: %w", err)
+	}
+
+	var s Selectors
+	if err := vp.Unmarshal(&s); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal selectors file: %w", err)
+	}
+
+	if err := v.Validate(s); err != nil {
+		return nil, fmt.Errorf("failed to validate selectors file: %w", err)
+	}
+
+	return &s, nil
+}
+
+func readFile(dir, retailerID string) (file []byte, ext string, err error) {
+	if dir != "" {
+		for _, ext := range exts {
+			name := filepath.Join(dir, retailerID+"."+ext)
+			file, err := os.ReadFile(name)
+			if err == nil {
+				return file, ext, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, "", err
+			}
+		}
+	}
+
+	for _, ext := range exts {
+		name := path.Join("selectors", retailerID+"."+ext)
+		file, err := root.Selectors.ReadFile(name)
+		if err == nil {
+			return file, ext, nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return nil, "", err
+		}
+	}
+
+	return nil, "", fmt.Errorf("no selectors for retailer %s", retailerID)
+}
@@ -6,21 +6,192 @@ import (
 	"time"
 )
 
-type Product struct {
-	ID        string     `db:"id" json:"id,omitempty"`
-	Name      string     `db:"name" json:"name,omitempty"`
-	Price     float64    `db:"price" json:"price,omitempty"`
-	Code      *string    `db:"code" json:"code,omitempty"`
-	CreatedAt time.Time  `db:"created_at" json:"created_at,omitempty"`
-	DeletedAt *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+type Retailer struct {
+	ID        string    `db:"id" json:"id,omitempty"`
+	Name      string    `db:"name" json:"name,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at,omitempty"`
 }
 
-type Error struct {
+type Store struct {
+	ID         string    `db:"id" json:"id,omitempty"`
+	RetailerID string    `db:"retailer_id" json:"retailer_id,omitempty"`
+	Code       string    `db:"code" json:"code,omitempty"`
+	Name       string    `db:"name" json:"name,omitempty"`
+	RegionID   string    `db:"region_id" json:"region_id,omitempty"`
+	PostalCode *string   `db:"postal_code" json:"postal_code,omitempty"`
+	CreatedAt  time.Time `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt  time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type Category struct {
 	ID         string     `db:"id" json:"id,omitempty"`
-	Message    string     `db:"message" json:"message,omitempty"`
-	Type       string     `db:"type" json:"type,omitempty"`
-	StackTrace string     `db:"stack_trace" json:"stack_trace,omitempty"`
-	Metadata   string     `db:"metadata" json:"metadata,omitempty"`
+	RetailerID string     `db:"retailer_id" json:"retailer_id,omitempty"`
+	ParentID   *string    `db:"parent_id" json:"parent_id,omitempty"`
+	Name       string     `db:"name" json:"name,omitempty"`
+	Slug       string     `db:"slug" json:"slug,omitempty"`
+	Path       string     `db:"path" json:"path,omitempty"`
+	Level      int        `db:"level" json:"level,omitempty"`
 	CreatedAt  time.Time  `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt  time.Time  `db:"updated_at" json:"updated_at,omitempty"`
 	DeletedAt  *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
 }
+
+type Product struct {
+	ID                   string     `db:"id" json:"id,omitempty"`
+	RetailerID           string     `db:"retailer_id" json:"retailer_id,omitempty"`
+	Name                 string     `db:"name" json:"name,omitempty"`
+	NormalizedName       string     `db:"normalized_name" json:"normalized_name,omitempty"`
+	Price                float64    `db:"price" json:"price,omitempty"`
+	ListPrice            *float64   `db:"list_price" json:"list_price,omitempty"`
+	PromoPrice           *float64   `db:"promo_price" json:"promo_price,omitempty"`
+	WholesalePrice       *float64   `db:"wholesale_price" json:"wholesale_price,omitempty"`
+	WholesaleMinQuantity *int       `db:"wholesale_min_quantity" json:"wholesale_min_quantity,omitempty"`
+	Brand                *string    `db:"brand" json:"brand,omitempty"`
+	ParsedBrand          *string    `db:"parsed_brand" json:"parsed_brand,omitempty"`
+	Quantity             *float64   `db:"quantity" json:"quantity,omitempty"`
+	Unit                 *string    `db:"unit" json:"unit,omitempty"`
+	PackCount            *int       `db:"pack_count" json:"pack_count,omitempty"`
+	Code                 *string    `db:"code" json:"code,omitempty"`
+	GTIN                 *string    `db:"gtin" json:"gtin,omitempty"`
+	Slug                 *string    `db:"slug" json:"slug,omitempty"`
+	URL                  *string    `db:"url" json:"url,omitempty"`
+	ImageURL             *string    `db:"image_url" json:"image_url,omitempty"`
+	Breadcrumb           *string    `db:"breadcrumb" json:"breadcrumb,omitempty"`
+	Seller               *string    `db:"seller" json:"seller,omitempty"`
+	Available            *bool      `db:"available" json:"available,omitempty"`
+	CategoryID           *string    `db:"category_id" json:"category_id,omitempty"`
+	CreatedAt            time.Time  `db:"created_at" json:"created_at,omitempty"`
+	DeletedAt            *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+type ProductSearchID struct {
+	ID        int    `db:"id" json:"id,omitempty"`
+	ProductID string `db:"product_id" json:"product_id,omitempty"`
+}
+
+type ListingChange struct {
+	ID        string    `db:"id" json:"id,omitempty"`
+	ProductID string    `db:"product_id" json:"product_id,omitempty"`
+	RunID     *string   `db:"run_id" json:"run_id,omitempty"`
+	Status    string    `db:"status" json:"status,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at,omitempty"`
+}
+
+type AlertRule struct {
+	ID               string     `db:"id" json:"id,omitempty"`
+	Name             string     `db:"name" json:"name,omitempty"`
+	Event            *string    `db:"event" json:"event,omitempty"`
+	RetailerID       *string    `db:"retailer_id" json:"retailer_id,omitempty"`
+	ProductID        *string    `db:"product_id" json:"product_id,omitempty"`
+	CategoryID       *string    `db:"category_id" json:"category_id,omitempty"`
+	ThresholdPercent float64    `db:"threshold_percent" json:"threshold_percent,omitempty"`
+	CreatedAt        time.Time  `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt        time.Time  `db:"updated_at" json:"updated_at,omitempty"`
+	DeletedAt        *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
+
+type CanonicalProduct struct {
+	ID             string    `db:"id" json:"id,omitempty"`
+	Name           string    `db:"name" json:"name,omitempty"`
+	NormalizedName string    `db:"normalized_name" json:"normalized_name,omitempty"`
+	Brand          *string   `db:"brand" json:"brand,omitempty"`
+	Quantity       *float64  `db:"quantity" json:"quantity,omitempty"`
+	Unit           *string   `db:"unit" json:"unit,omitempty"`
+	PackCount      *int      `db:"pack_count" json:"pack_count,omitempty"`
+	GTIN           *string   `db:"gtin" json:"gtin,omitempty"`
+	CreatedAt      time.Time `db:"created_at" json:"created_at,omitempty"`
+}
+
+type ProductMatch struct {
+	ID                 string    `db:"id" json:"id,omitempty"`
+	ProductID          string    `db:"product_id" json:"product_id,omitempty"`
+	CanonicalProductID string    `db:"canonical_product_id" json:"canonical_product_id,omitempty"`
+	RetailerID         string    `db:"retailer_id" json:"retailer_id,omitempty"`
+	Method             string    `db:"method" json:"method,omitempty"`
+	Status             string    `db:"status" json:"status,omitempty"`
+	Confidence         float64   `db:"confidence" json:"confidence,omitempty"`
+	CreatedAt          time.Time `db:"created_at" json:"created_at,omitempty"`
+	UpdatedAt          time.Time `db:"updated_at" json:"updated_at,omitempty"`
+}
+
+type PriceObservation struct {
+	ID                   string    `db:"id" json:"id,omitempty"`
+	ProductID            string    `db:"product_id" json:"product_id,omitempty"`
+	Price                float64   `db:"price" json:"price,omitempty"`
+	ListPrice            *float64  `db:"list_price" json:"list_price,omitempty"`
+	PromoPrice           *float64  `db:"promo_price" json:"promo_price,omitempty"`
+	WholesalePrice       *float64  `db:"wholesale_price" json:"wholesale_price,omitempty"`
+	WholesaleMinQuantity *int      `db:"wholesale_min_quantity" json:"wholesale_min_quantity,omitempty"`
+	UnitPrice            *float64  `db:"unit_price" json:"unit_price,omitempty"`
+	UnitPriceUnit        *string   `db:"unit_price_unit" json:"unit_price_unit,omitempty"`
+	Available            *bool     `db:"available" json:"available,omitempty"`
+	Source               string    `db:"source" json:"source,omitempty"`
+	RunID                *string   `db:"run_id" json:"run_id,omitempty"`
+	StoreID              *string   `db:"store_id" json:"store_id,omitempty"`
+	ObservedAt           time.Time `db:"observed_at" json:"observed_at,omitempty"`
+}
+
+type ProductPrice struct {
+	ID                   string    `db:"id" json:"id,omitempty"`
+	ProductID            string    `db:"product_id" json:"product_id,omitempty"`
+	StoreID              *string   `db:"store_id" json:"store_id,omitempty"`
+	Price                float64   `db:"price" json:"price,omitempty"`
+	ListPrice            *float64  `db:"list_price" json:"list_price,omitempty"`
+	PromoPrice           *float64  `db:"promo_price" json:"promo_price,omitempty"`
+	WholesalePrice       *float64  `db:"wholesale_price" json:"wholesale_price,omitempty"`
+	WholesaleMinQuantity *int      `db:"wholesale_min_quantity" json:"wholesale_min_quantity,omitempty"`
+	UnitPrice            *float64  `db:"unit_price" json:"unit_price,omitempty"`
+	UnitPriceUnit        *string   `db:"unit_price_unit" json:"unit_price_unit,omitempty"`
+	Available            *bool     `db:"available" json:"available,omitempty"`
+	ObservedAt           time.Time `db:"observed_at" json:"observed_at,omitempty"`
+}
+
+type ScrapeRun struct {
+	ID             string     `db:"id" json:"id,omitempty"`
+	RetailerID     string     `db:"retailer_id" json:"retailer_id,omitempty"`
+	StoreID        *string    `db:"store_id" json:"store_id,omitempty"`
+	Source         string     `db:"source" json:"source,omitempty"`
+	Kind           string     `db:"kind" json:"kind,omitempty"`
+	Status         string     `db:"status" json:"status,omitempty"`
+	PageSize       *int       `db:"page_size" json:"page_size,omitempty"`
+	ProductCount   int        `db:"product_count" json:"product_count,omitempty"`
+	ErrorCount     int        `db:"error_count" json:"error_count,omitempty"`
+	CategoryCounts string     `db:"category_counts" json:"category_counts,omitempty"`
+	StartedAt      time.Time  `db:"started_at" json:"started_at,omitempty"`
+	FinishedAt     *time.Time `db:"finished_at" json:"finished_at,omitempty"`
+}
+
+type ScrapeCheckpoint struct {
+	ID           string    `db:"id" json:"id,omitempty"`
+	RunID        string    `db:"run_id" json:"run_id,omitempty"`
+	Category     string    `db:"category" json:"category,omitempty"`
+	Page         int       `db:"page" json:"page,omitempty"`
+	TotalPages   int       `db:"total_pages" json:"total_pages,omitempty"`
+	ProductCount int       `db:"product_count" json:"product_count,omitempty"`
+	CreatedAt    time.Time `db:"created_at" json:"created_at,omitempty"`
+}
+
+type RawPayload struct {
+	ID        string    `db:"id" json:"id,omitempty"`
+	RunID     string    `db:"run_id" json:"run_id,omitempty"`
+	Category  string    `db:"category" json:"category,omitempty"`
+	Page      int       `db:"page" json:"page,omitempty"`
+	Size      int       `db:"size" json:"size,omitempty"`
+	Body      []byte    `db:"body" json:"body,omitempty"`
+	CreatedAt time.Time `db:"created_at" json:"created_at,omitempty"`
+}
+
+type Error struct {
+	ID                  string     `db:"id" json:"id,omitempty"`
+	RunID               *string    `db:"run_id" json:"run_id,omitempty"`
+	Message             string     `db:"message" json:"message,omitempty"`
+	Type                string     `db:"type" json:"type,omitempty"`
+	StackTrace          string     `db:"stack_trace" json:"stack_trace,omitempty"`
+	Metadata            string     `db:"metadata" json:"metadata,omitempty"`
+	Attempts            int        `db:"attempts" json:"attempts,omitempty"`
+	LastFailure         *string    `db:"last_failure" json:"last_failure,omitempty"`
+	LastAttemptAt       *time.Time `db:"last_attempt_at" json:"last_attempt_at,omitempty"`
+	PermanentlyFailedAt *time.Time `db:"permanently_failed_at" json:"permanently_failed_at,omitempty"`
+	CreatedAt           time.Time  `db:"created_at" json:"created_at,omitempty"`
+	DeletedAt           *time.Time `db:"deleted_at" json:"deleted_at,omitempty"`
+}
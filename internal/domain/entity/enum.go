@@ -0,0 +1,69 @@
+package entity
+
+// Source identifies which scraper produced a record.
+type Source string
+
+const (
+	SourceAPI Source = "api"
+	SourceWeb Source = "web"
+)
+
+// RunStatus is the outcome of a scrape run.
+type RunStatus string
+
+const (
+	RunStatusRunning   RunStatus = "running"
+	RunStatusSucceeded RunStatus = "succeeded"
+	RunStatusPartial   RunStatus = "partial"
+	RunStatusFailed    RunStatus = "failed"
+)
+
+// RunKind is what a scrape run crawls.
+type RunKind string
+
+const (
+	// RunKindCrawl crawls the whole catalog.
+	RunKindCrawl RunKind = "crawl"
+	// RunKindResume is a crawl continued from where it stopped, which
+	// covers the whole catalog once it succeeds.
+	RunKindResume RunKind = "resume"
+	// RunKindRetry only requests again the pages earlier runs failed.
+	RunKindRetry RunKind = "retry"
+)
+
+// MatchMethod is how a product was matched to its canonical product.
+type MatchMethod string
+
+const (
+	MatchMethodGTIN   MatchMethod = "gtin"
+	MatchMethodName   MatchMethod = "name"
+	MatchMethodNew    MatchMethod = "new"
+	MatchMethodManual MatchMethod = "manual"
+)
+
+// MatchStatus tells whether a match still needs to be reviewed.
+type MatchStatus string
+
+const (
+	MatchStatusConfirmed MatchStatus = "confirmed"
+	MatchStatusPending   MatchStatus = "pending"
+)
+
+// ListingStatus is the transition recorded when a product leaves or comes
+// back to the catalog of its retailer.
+type ListingStatus string
+
+const (
+	ListingStatusDelisted ListingStatus = "delisted"
+	ListingStatusRelisted ListingStatus = "relisted"
+)
+
+// AlertEvent is a change of a product that alert rules can watch.
+type AlertEvent string
+
+const (
+	AlertEventPriceDrop  AlertEvent = "price_drop"
+	AlertEventPriceRise  AlertEvent = "price_rise"
+	AlertEventNewProduct AlertEvent = "new_product"
+	AlertEventDelisted   AlertEvent = "delisted"
+)
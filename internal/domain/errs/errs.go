@@ -13,7 +13,12 @@ const (
 	ErrTypeFailedProcessingProductsPage ErrType = "failed_processing_products_page"
 	ErrTypeFailedProcessingCategoryPage ErrType = "failed_processing_category_page"
 	ErrTypeFailedListingProducts        ErrType = "failed_listing_products"
+	ErrTypeFailedListingCategories      ErrType = "failed_listing_categories"
+	ErrTypeFailedListingStores          ErrType = "failed_listing_stores"
+	ErrTypeFailedRequestingProductsPage ErrType = "failed_requesting_products_page"
 	ErrTypeFailedSavingProducts         ErrType = "failed_saving_products"
+	ErrTypeFailedSendingAlerts          ErrType = "failed_sending_alerts"
+	ErrTypeMissingFieldsSpike           ErrType = "missing_fields_spike"
 )
 
 type Err struct {
@@ -0,0 +1,294 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier"
+)
+
+type AlertPriceChangesUseCase struct {
+	db db.DB
+	n  notifier.Notifier
+}
+
+func NewAlertPriceChangesUseCase(
+	db db.DB,
+	n notifier.Notifier,
+) *AlertPriceChangesUseCase {
+	return &AlertPriceChangesUseCase{
+		db: db,
+		n:  n,
+	}
+}
+
+// Execute compares the observations the run just recorded with the previous
+// observation of each product in the same store, and notifies the price
+// drops, price rises and new products the alert rules watch.
+//
+// A product is new when no earlier run of the store observed it. The first
+// run of a store reports no new products, as every product would be.
+func (u *AlertPriceChangesUseCase) Execute(
+	ctx context.Context,
+	run *RunTracker,
+	observations []entity.PriceObservation,
+) error {
+	if len(observations) == 0 {
+		return nil
+	}
+
+	rules, err := loadAlertRules(ctx, u.db, run.RetailerID)
+	if err != nil {
+		return errs.New(err)
+	}
+	if rules == nil {
+		return nil
+	}
+
+	productIDs := make([]string, len(observations))
+	for i, observation := range observations {
+		productIDs[i] = observation.ProductID
+	}
+
+	previousObservations, err := u.db.ListPreviousPriceObservations(
+		ctx,
+		productIDs,
+		run.StoreID,
+		run.ID,
+	)
+	if err != nil {
+		return errs.New(err)
+	}
+	previousByProductID := map[string]entity.PriceObservation{}
+	for _, observation := range previousObservations {
+		previousByProductID[observation.ProductID] = observation
+	}
+
+	storeObservedBefore := false
+	if len(previousByProductID) < len(observations) {
+		storeObservedBefore, err = u.storeObservedBefore(ctx, run)
+		if err != nil {
+			return errs.New(err)
+		}
+	}
+
+	products, err := u.db.ListProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return errs.New(err)
+	}
+	productsByID := map[string]entity.Product{}
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+
+	now := time.Now()
+	events := []notifier.Event{}
+	for _, observation := range observations {
+		event := notifier.Event{
+			Product:    productsByID[observation.ProductID],
+			RunID:      &run.ID,
+			StoreID:    run.StoreID,
+			Price:      observation.Price,
+			OccurredAt: now,
+		}
+
+		previous, ok := previousByProductID[observation.ProductID]
+		switch {
+		case !ok && storeObservedBefore:
+			event.Type = entity.AlertEventNewProduct
+
+		case ok && previous.Price > 0 && observation.Price != previous.Price:
+			changePercent := 100 * (observation.Price - previous.Price) /
+				previous.Price
+			event.Type = entity.AlertEventPriceRise
+			if changePercent < 0 {
+				event.Type = entity.AlertEventPriceDrop
+			}
+			event.PreviousPrice = &previous.Price
+			event.ChangePercent = &changePercent
+
+		default:
+			continue
+		}
+
+		events = append(events, rules.match(event)...)
+	}
+
+	if err := u.n.Notify(ctx, events); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// storeObservedBefore reports whether an earlier run of the same source and
+// store was made.
+func (u *AlertPriceChangesUseCase) storeObservedBefore(
+	ctx context.Context,
+	run *RunTracker,
+) (bool, error) {
+	runs, err := u.db.ListScrapeRuns(ctx, run.RetailerID)
+	if err != nil {
+		return false, errs.New(err)
+	}
+
+	for _, r := range runs {
+		if r.ID != run.ID &&
+			r.Source == run.Source &&
+			equal(r.StoreID, run.StoreID) &&
+			r.StartedAt.Before(run.StartedAt) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+type AlertDelistingsUseCase struct {
+	db db.DB
+	n  notifier.Notifier
+}
+
+func NewAlertDelistingsUseCase(
+	db db.DB,
+	n notifier.Notifier,
+) *AlertDelistingsUseCase {
+	return &AlertDelistingsUseCase{
+		db: db,
+		n:  n,
+	}
+}
+
+// Execute notifies the delistings of the products the alert rules watch.
+func (u *AlertDelistingsUseCase) Execute(
+	ctx context.Context,
+	run *RunTracker,
+	products []entity.Product,
+) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	rules, err := loadAlertRules(ctx, u.db, run.RetailerID)
+	if err != nil {
+		return errs.New(err)
+	}
+	if rules == nil {
+		return nil
+	}
+
+	now := time.Now()
+	events := []notifier.Event{}
+	for _, product := range products {
+		events = append(events, rules.match(notifier.Event{
+			Type:       entity.AlertEventDelisted,
+			Product:    product,
+			RunID:      &run.ID,
+			StoreID:    run.StoreID,
+			Price:      product.Price,
+			OccurredAt: now,
+		})...)
+	}
+
+	if err := u.n.Notify(ctx, events); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// alertRules are the alert rules along with the parent of every category of
+// the retailer, so rules watching a category also watch its subcategories.
+type alertRules struct {
+	rules     []entity.AlertRule
+	parentIDs map[string]*string
+}
+
+// loadAlertRules loads the alert rules that may watch products of the
+// retailer, returning nil when there are none.
+func loadAlertRules(
+	ctx context.Context,
+	db db.DB,
+	retailerID string,
+) (*alertRules, error) {
+	allRules, err := db.ListAlertRules(ctx)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	rules := []entity.AlertRule{}
+	watchesCategories := false
+	for _, rule := range allRules {
+		if rule.RetailerID != nil && *rule.RetailerID != retailerID {
+			continue
+		}
+		rules = append(rules, rule)
+		watchesCategories = watchesCategories || rule.CategoryID != nil
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+
+	parentIDs := map[string]*string{}
+	if watchesCategories {
+		categories, err := db.ListCategories(ctx, retailerID)
+		if err != nil {
+			return nil, errs.New(err)
+		}
+		for _, category := range categories {
+			parentIDs[category.ID] = category.ParentID
+		}
+	}
+
+	return &alertRules{
+		rules:     rules,
+		parentIDs: parentIDs,
+	}, nil
+}
+
+// match returns a copy of the event for every rule that watches it. A rule
+// watches every event, product and category it does not name, and price
+// changes of at least its threshold.
+func (r *alertRules) match(event notifier.Event) []notifier.Event {
+	events := []notifier.Event{}
+	for _, rule := range r.rules {
+		if rule.Event != nil && *rule.Event != string(event.Type) {
+			continue
+		}
+		if rule.ProductID != nil && *rule.ProductID != event.Product.ID {
+			continue
+		}
+		if rule.CategoryID != nil &&
+			!r.inCategory(event.Product.CategoryID, *rule.CategoryID) {
+			continue
+		}
+		if event.ChangePercent != nil &&
+			math.Abs(*event.ChangePercent) < rule.ThresholdPercent {
+			continue
+		}
+
+		event.RuleID = rule.ID
+		event.RuleName = rule.Name
+		events = append(events, event)
+	}
+
+	return events
+}
+
+// inCategory reports whether the category is the given one or one of its
+// subcategories.
+func (r *alertRules) inCategory(categoryID *string, ancestorID string) bool {
+	// The depth is bounded in case the tree has a cycle.
+	for depth := 0; categoryID != nil && depth < 100; depth++ {
+		if *categoryID == ancestorID {
+			return true
+		}
+		categoryID = r.parentIDs[*categoryID]
+	}
+
+	return false
+}
@@ -0,0 +1,204 @@
+package usecase
+
+import (
+	"context"
+	"strings"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+type SearchProductsUseCase struct {
+	db db.DB
+}
+
+func NewSearchProductsUseCase(db db.DB) *SearchProductsUseCase {
+	return &SearchProductsUseCase{
+		db: db,
+	}
+}
+
+// ProductListing is a product along with its current prices, one for each
+// store it was last observed at, the lowest first.
+type ProductListing struct {
+	Product entity.Product
+	Prices  []entity.ProductPrice
+}
+
+// Execute lists a page of the products matching the query and the filter,
+// along with how many match them in total. The query ignores case and
+// accents, and the best matches come first. Without a query, the products
+// are listed by name. Their prices are those at the store of the filter,
+// when it has one.
+func (u *SearchProductsUseCase) Execute(
+	ctx context.Context,
+	query string,
+	filter db.ProductFilter,
+) ([]ProductListing, int, error) {
+	var products []entity.Product
+	var total int
+	var err error
+	if strings.TrimSpace(query) == "" {
+		products, total, err = u.db.FindProducts(ctx, filter)
+	} else {
+		products, total, err = u.db.SearchProducts(ctx, query, filter)
+	}
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	prices, err := u.db.ListProductPrices(ctx, productIDs, filter.StoreID)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	pricesByProduct := map[string][]entity.ProductPrice{}
+	for _, price := range prices {
+		pricesByProduct[price.ProductID] = append(
+			pricesByProduct[price.ProductID],
+			price,
+		)
+	}
+
+	listings := make([]ProductListing, len(products))
+	for i, product := range products {
+		listings[i] = ProductListing{
+			Product: product,
+			Prices:  pricesByProduct[product.ID],
+		}
+	}
+
+	return listings, total, nil
+}
+
+// ProductHistory is a product along with its current prices and a page of
+// its price observations, the latest first.
+type ProductHistory struct {
+	Product      entity.Product
+	Prices       []entity.ProductPrice
+	Observations []entity.PriceObservation
+	// Total is how many observations match the filter.
+	Total int
+}
+
+type GetProductHistoryUseCase struct {
+	db db.DB
+}
+
+func NewGetProductHistoryUseCase(db db.DB) *GetProductHistoryUseCase {
+	return &GetProductHistoryUseCase{
+		db: db,
+	}
+}
+
+// Execute returns the product with the page of its price observations
+// matching the filter, or nil when there is no such product.
+func (u *GetProductHistoryUseCase) Execute(
+	ctx context.Context,
+	productID string,
+	filter db.PriceObservationFilter,
+) (*ProductHistory, error) {
+	products, err := u.db.ListProductsByIDs(ctx, []string{productID})
+	if err != nil {
+		return nil, errs.New(err)
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+
+	prices, err := u.db.ListProductPrices(ctx, []string{productID}, "")
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	filter.ProductID = productID
+	observations, total, err := u.db.FindPriceObservations(ctx, filter)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	return &ProductHistory{
+		Product:      products[0],
+		Prices:       prices,
+		Observations: observations,
+		Total:        total,
+	}, nil
+}
+
+type ListCategoriesUseCase struct {
+	db db.DB
+}
+
+func NewListCategoriesUseCase(db db.DB) *ListCategoriesUseCase {
+	return &ListCategoriesUseCase{
+		db: db,
+	}
+}
+
+// Execute lists a page of the categories matching the filter, along with
+// how many match it in total.
+func (u *ListCategoriesUseCase) Execute(
+	ctx context.Context,
+	filter db.CategoryFilter,
+) ([]entity.Category, int, error) {
+	categories, total, err := u.db.FindCategories(ctx, filter)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return categories, total, nil
+}
+
+type ListScrapeRunsUseCase struct {
+	db db.DB
+}
+
+func NewListScrapeRunsUseCase(db db.DB) *ListScrapeRunsUseCase {
+	return &ListScrapeRunsUseCase{
+		db: db,
+	}
+}
+
+// Execute lists a page of the runs matching the filter, the latest first,
+// along with how many match it in total.
+func (u *ListScrapeRunsUseCase) Execute(
+	ctx context.Context,
+	filter db.ScrapeRunFilter,
+) ([]entity.ScrapeRun, int, error) {
+	runs, total, err := u.db.FindScrapeRuns(ctx, filter)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return runs, total, nil
+}
+
+type ListErrorsUseCase struct {
+	db db.DB
+}
+
+func NewListErrorsUseCase(db db.DB) *ListErrorsUseCase {
+	return &ListErrorsUseCase{
+		db: db,
+	}
+}
+
+// Execute lists a page of the errors matching the filter, the latest first,
+// along with how many match it in total.
+func (u *ListErrorsUseCase) Execute(
+	ctx context.Context,
+	filter db.ErrorFilter,
+) ([]entity.Error, int, error) {
+	errors, total, err := u.db.FindErrors(ctx, filter)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return errors, total, nil
+}
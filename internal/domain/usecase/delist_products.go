@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+type DelistProductsUseCase struct {
+	db db.DB
+}
+
+func NewDelistProductsUseCase(db db.DB) *DelistProductsUseCase {
+	return &DelistProductsUseCase{
+		db: db,
+	}
+}
+
+// Execute delists the products of the retailer the run did not observe,
+// unless another store still listed them in its latest succeeded crawl, as
+// the products are shared by every store. It must only be called once the
+// run crawled the whole catalog, and does nothing for retries or unless the
+// run succeeded, as a product missing from them may just not have been
+// scraped. The delisted products are returned.
+func (u *DelistProductsUseCase) Execute(
+	ctx context.Context,
+	run *RunTracker,
+) ([]entity.Product, error) {
+	if run.Kind == string(entity.RunKindRetry) ||
+		run.Status != string(entity.RunStatusSucceeded) ||
+		run.ProductCount == 0 {
+		return nil, nil
+	}
+
+	products, err := u.db.ListUnobservedProducts(ctx, run.ScrapeRun)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	productIDs := make([]string, len(products))
+	for i, product := range products {
+		productIDs[i] = product.ID
+	}
+
+	err = u.db.ChangeProductListings(
+		ctx,
+		productIDs,
+		&run.ID,
+		entity.ListingStatusDelisted,
+	)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	if len(productIDs) > 0 {
+		slog.Info(
+			"products delisted",
+			"run_id", run.ID,
+			"retailer_id", run.RetailerID,
+			"products", len(productIDs),
+		)
+	}
+
+	return products, nil
+}
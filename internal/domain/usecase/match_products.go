@@ -0,0 +1,278 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"slices"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/normalize"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+const (
+	// Name matches scoring at least autoConfirmConfidence are confirmed
+	// right away, while those scoring at least minMatchConfidence wait to be
+	// reviewed. Products scoring less become canonical products themselves.
+	autoConfirmConfidence = 0.95
+	minMatchConfidence    = 0.6
+)
+
+type MatchProductsUseCase struct {
+	db db.DB
+}
+
+func NewMatchProductsUseCase(db db.DB) *MatchProductsUseCase {
+	return &MatchProductsUseCase{
+		db: db,
+	}
+}
+
+type matchCandidate struct {
+	canonicalProduct entity.CanonicalProduct
+	trigrams         normalize.TrigramSet
+	retailerIDs      map[string]bool
+}
+
+// Execute links every unmatched product to a canonical product, the same
+// item as listed by every retailer. Products are matched by GTIN when they
+// have one, and otherwise by the similarity of their name, brand and size.
+//
+// A retailer lists an item only once, so a product is never matched by name
+// to a canonical product another listing of its retailer is matched to.
+func (u *MatchProductsUseCase) Execute(ctx context.Context) error {
+	products, err := u.db.ListUnmatchedProducts(ctx)
+	if err != nil {
+		return errs.New(err)
+	}
+	if len(products) == 0 {
+		return nil
+	}
+
+	canonicalProducts, err := u.db.ListCanonicalProducts(ctx)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	existingMatches, err := u.db.ListProductMatches(ctx)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	candidates := []*matchCandidate{}
+	candidatesByID := map[string]*matchCandidate{}
+	candidatesByGTIN := map[string]*matchCandidate{}
+	for _, canonicalProduct := range canonicalProducts {
+		candidate := newMatchCandidate(canonicalProduct)
+		candidates = append(candidates, candidate)
+		candidatesByID[canonicalProduct.ID] = candidate
+		if canonicalProduct.GTIN != nil {
+			candidatesByGTIN[*canonicalProduct.GTIN] = candidate
+		}
+	}
+	for _, match := range existingMatches {
+		if candidate, ok := candidatesByID[match.CanonicalProductID]; ok {
+			candidate.retailerIDs[match.RetailerID] = true
+		}
+	}
+
+	// Products with a GTIN go first, so products without one can be matched
+	// by name to the canonical products they create.
+	slices.SortStableFunc(products, func(a, b entity.Product) int {
+		if (a.GTIN != nil) == (b.GTIN != nil) {
+			return 0
+		}
+		if a.GTIN != nil {
+			return -1
+		}
+		return 1
+	})
+
+	newCandidates := []*matchCandidate{}
+	matches := make([]entity.ProductMatch, len(products))
+	matchedCandidates := make([]*matchCandidate, len(products))
+	for i, product := range products {
+		match := entity.ProductMatch{
+			ProductID:  product.ID,
+			RetailerID: product.RetailerID,
+			Method:     string(entity.MatchMethodName),
+			Status:     string(entity.MatchStatusConfirmed),
+			Confidence: 1,
+		}
+
+		var candidate *matchCandidate
+		if product.GTIN != nil {
+			candidate = candidatesByGTIN[*product.GTIN]
+			match.Method = string(entity.MatchMethodGTIN)
+		} else {
+			candidate, match.Confidence = bestMatchCandidate(
+				product,
+				candidates,
+			)
+			if match.Confidence < autoConfirmConfidence {
+				match.Status = string(entity.MatchStatusPending)
+			}
+		}
+
+		if candidate == nil || match.Confidence < minMatchConfidence {
+			candidate = newMatchCandidate(newCanonicalProduct(product))
+			candidates = append(candidates, candidate)
+			newCandidates = append(newCandidates, candidate)
+			if product.GTIN != nil {
+				candidatesByGTIN[*product.GTIN] = candidate
+			}
+
+			match.Method = string(entity.MatchMethodNew)
+			match.Status = string(entity.MatchStatusConfirmed)
+			match.Confidence = 1
+		}
+
+		candidate.retailerIDs[product.RetailerID] = true
+		matches[i] = match
+		matchedCandidates[i] = candidate
+	}
+
+	newCanonicalProducts := make([]entity.CanonicalProduct, len(newCandidates))
+	for i, candidate := range newCandidates {
+		newCanonicalProducts[i] = candidate.canonicalProduct
+	}
+	err = u.db.CreateCanonicalProducts(ctx, newCanonicalProducts)
+	if err != nil {
+		return errs.New(err)
+	}
+	for i, candidate := range newCandidates {
+		candidate.canonicalProduct.ID = newCanonicalProducts[i].ID
+	}
+
+	pendingCount := 0
+	for i := range matches {
+		matches[i].CanonicalProductID = matchedCandidates[i].canonicalProduct.ID
+		if matches[i].Status == string(entity.MatchStatusPending) {
+			pendingCount++
+		}
+	}
+
+	if err := u.db.CreateProductMatches(ctx, matches); err != nil {
+		return errs.New(err)
+	}
+
+	slog.Info(
+		"products matched",
+		"products", len(matches),
+		"new_canonical_products", len(newCanonicalProducts),
+		"pending_review", pendingCount,
+	)
+
+	return nil
+}
+
+func newMatchCandidate(
+	canonicalProduct entity.CanonicalProduct,
+) *matchCandidate {
+	return &matchCandidate{
+		canonicalProduct: canonicalProduct,
+		trigrams:         normalize.Trigrams(canonicalProduct.Name),
+		retailerIDs:      map[string]bool{},
+	}
+}
+
+func newCanonicalProduct(product entity.Product) entity.CanonicalProduct {
+	return entity.CanonicalProduct{
+		Name:           product.Name,
+		NormalizedName: product.NormalizedName,
+		Brand:          product.Brand,
+		Quantity:       product.Quantity,
+		Unit:           product.Unit,
+		PackCount:      product.PackCount,
+		GTIN:           product.GTIN,
+	}
+}
+
+// bestMatchCandidate returns the candidate the product is most likely the
+// same item as, along with the confidence of the match.
+func bestMatchCandidate(
+	product entity.Product,
+	candidates []*matchCandidate,
+) (*matchCandidate, float64) {
+	trigrams := normalize.Trigrams(product.Name)
+
+	var best *matchCandidate
+	var bestConfidence float64
+	for _, candidate := range candidates {
+		if candidate.retailerIDs[product.RetailerID] {
+			continue
+		}
+
+		confidence := matchConfidence(product, trigrams, candidate)
+		if confidence > bestConfidence {
+			best, bestConfidence = candidate, confidence
+		}
+	}
+
+	return best, bestConfidence
+}
+
+// matchConfidence weighs the similarity of the names, brands and sizes of
+// the product and the candidate. Products of different sizes are never the
+// same item, while an unknown brand or size counts as half a match. Brands
+// are only compared when both retailers give one, never guessed from names.
+func matchConfidence(
+	product entity.Product,
+	trigrams normalize.TrigramSet,
+	candidate *matchCandidate,
+) float64 {
+	const nameWeight, brandWeight, sizeWeight = 0.6, 0.2, 0.2
+
+	canonicalProduct := candidate.canonicalProduct
+
+	sizeScore := 0.5
+	total, base, ok := productSize(
+		product.Quantity,
+		product.Unit,
+		product.PackCount,
+	)
+	candidateTotal, candidateBase, candidateOK := productSize(
+		canonicalProduct.Quantity,
+		canonicalProduct.Unit,
+		canonicalProduct.PackCount,
+	)
+	if ok && candidateOK {
+		if base != candidateBase ||
+			math.Abs(total-candidateTotal) > 0.01*max(total, candidateTotal) {
+			return 0
+		}
+		sizeScore = 1
+	}
+
+	brandScore := 0.5
+	if product.Brand != nil && canonicalProduct.Brand != nil {
+		brandScore = 0
+		if normalize.Name(*product.Brand) ==
+			normalize.Name(*canonicalProduct.Brand) {
+			brandScore = 1
+		}
+	}
+
+	nameScore := trigrams.Similarity(candidate.trigrams)
+
+	return nameWeight*nameScore + brandWeight*brandScore + sizeWeight*sizeScore
+}
+
+func productSize(
+	quantity *float64,
+	unit *string,
+	packCount *int,
+) (float64, normalize.Unit, bool) {
+	if quantity == nil || unit == nil {
+		return 0, "", false
+	}
+
+	count := 1
+	if packCount != nil {
+		count = *packCount
+	}
+
+	return normalize.Total(*quantity, normalize.Unit(*unit), count)
+}
@@ -0,0 +1,369 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/normalize"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+// matchDB keeps the records the matching reads and writes in memory.
+type matchDB struct {
+	db.DB
+	products          []entity.Product
+	canonicalProducts []entity.CanonicalProduct
+	matches           []entity.ProductMatch
+}
+
+func (d *matchDB) ListUnmatchedProducts(
+	context.Context,
+) ([]entity.Product, error) {
+	return d.products, nil
+}
+
+func (d *matchDB) ListCanonicalProducts(
+	context.Context,
+) ([]entity.CanonicalProduct, error) {
+	return d.canonicalProducts, nil
+}
+
+func (d *matchDB) ListProductMatches(
+	context.Context,
+) ([]entity.ProductMatch, error) {
+	return d.matches, nil
+}
+
+func (d *matchDB) CreateCanonicalProducts(
+	_ context.Context,
+	canonicalProducts []entity.CanonicalProduct,
+) error {
+	for i := range canonicalProducts {
+		canonicalProducts[i].ID = "new-" + strconv.Itoa(i)
+	}
+	d.canonicalProducts = append(d.canonicalProducts, canonicalProducts...)
+	return nil
+}
+
+func (d *matchDB) CreateProductMatches(
+	_ context.Context,
+	matches []entity.ProductMatch,
+) error {
+	d.matches = append(d.matches, matches...)
+	return nil
+}
+
+func TestMatchProducts(t *testing.T) {
+	rice := entity.CanonicalProduct{
+		ID:        "rice",
+		Name:      "Arroz Branco Tio João 5kg",
+		Brand:     ptr("Tio João"),
+		Quantity:  ptr(5.0),
+		Unit:      ptr(string(normalize.UnitKilogram)),
+		PackCount: ptr(1),
+		GTIN:      ptr("7893500018148"),
+	}
+	beer := entity.CanonicalProduct{
+		ID:   "beer",
+		Name: "Cerveja Heineken Long Neck",
+	}
+
+	tests := []struct {
+		name             string
+		canonicalProduct entity.CanonicalProduct
+		// matchedRetailer already has a listing matched to the canonical
+		// product, when set.
+		matchedRetailer string
+		product         entity.Product
+		// wantMatched is whether the product is matched to the canonical
+		// product rather than to a new one.
+		wantMatched bool
+		wantMethod  entity.MatchMethod
+		wantStatus  entity.MatchStatus
+	}{
+		{
+			name:             "GTIN regardless of the name",
+			canonicalProduct: rice,
+			product: entity.Product{
+				Name: "Cesta Básica Econômica",
+				GTIN: ptr("7893500018148"),
+			},
+			wantMatched: true,
+			wantMethod:  entity.MatchMethodGTIN,
+			wantStatus:  entity.MatchStatusConfirmed,
+		},
+		{
+			name:             "unknown GTIN is not matched by name",
+			canonicalProduct: rice,
+			product: entity.Product{
+				Name:      "Arroz Branco Tio João 5kg",
+				Brand:     ptr("Tio João"),
+				Quantity:  ptr(5.0),
+				Unit:      ptr(string(normalize.UnitKilogram)),
+				PackCount: ptr(1),
+				GTIN:      ptr("7896006711155"),
+			},
+			wantMethod: entity.MatchMethodNew,
+			wantStatus: entity.MatchStatusConfirmed,
+		},
+		{
+			name:             "same name, brand and size is confirmed",
+			canonicalProduct: rice,
+			product: entity.Product{
+				Name:      "ARROZ BRANCO TIO JOAO 5KG",
+				Brand:     ptr("TIO JOÃO"),
+				Quantity:  ptr(5000.0),
+				Unit:      ptr(string(normalize.UnitGram)),
+				PackCount: ptr(1),
+			},
+			wantMatched: true,
+			wantMethod:  entity.MatchMethodName,
+			wantStatus:  entity.MatchStatusConfirmed,
+		},
+		{
+			name:             "brand given by one retailer only is reviewed",
+			canonicalProduct: rice,
+			product: entity.Product{
+				Name:        "Arroz Branco Tio João 5kg",
+				ParsedBrand: ptr("Tio João"),
+				Quantity:    ptr(5.0),
+				Unit:        ptr(string(normalize.UnitKilogram)),
+				PackCount:   ptr(1),
+			},
+			wantMatched: true,
+			wantMethod:  entity.MatchMethodName,
+			wantStatus:  entity.MatchStatusPending,
+		},
+		{
+			name:             "different brand is reviewed",
+			canonicalProduct: rice,
+			product: entity.Product{
+				Name:      "Arroz Branco Tio João 5kg",
+				Brand:     ptr("Camil"),
+				Quantity:  ptr(5.0),
+				Unit:      ptr(string(normalize.UnitKilogram)),
+				PackCount: ptr(1),
+			},
+			wantMatched: true,
+			wantMethod:  entity.MatchMethodName,
+			wantStatus:  entity.MatchStatusPending,
+		},
+		{
+			name:             "different size is never matched",
+			canonicalProduct: rice,
+			product: entity.Product{
+				Name:      "Arroz Branco Tio João 1kg",
+				Brand:     ptr("Tio João"),
+				Quantity:  ptr(1.0),
+				Unit:      ptr(string(normalize.UnitKilogram)),
+				PackCount: ptr(1),
+			},
+			wantMethod: entity.MatchMethodNew,
+			wantStatus: entity.MatchStatusConfirmed,
+		},
+		{
+			name:             "similar name is reviewed",
+			canonicalProduct: beer,
+			product:          entity.Product{Name: "Cerveja Heineken Lata"},
+			wantMatched:      true,
+			wantMethod:       entity.MatchMethodName,
+			wantStatus:       entity.MatchStatusPending,
+		},
+		{
+			name: "similar name of a different brand is not matched",
+			canonicalProduct: func() entity.CanonicalProduct {
+				canonicalProduct := beer
+				canonicalProduct.Brand = ptr("Heineken")
+				return canonicalProduct
+			}(),
+			product: entity.Product{
+				Name:  "Cerveja Heineken Lata",
+				Brand: ptr("Amstel"),
+			},
+			wantMethod: entity.MatchMethodNew,
+			wantStatus: entity.MatchStatusConfirmed,
+		},
+		{
+			name:             "retailer already matched",
+			canonicalProduct: rice,
+			matchedRetailer:  "atacadao",
+			product: entity.Product{
+				Name:      "Arroz Branco Tio João 5kg",
+				Brand:     ptr("Tio João"),
+				Quantity:  ptr(5.0),
+				Unit:      ptr(string(normalize.UnitKilogram)),
+				PackCount: ptr(1),
+			},
+			wantMethod: entity.MatchMethodNew,
+			wantStatus: entity.MatchStatusConfirmed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.product.ID = "product"
+			tt.product.RetailerID = "atacadao"
+
+			d := &matchDB{
+				products:          []entity.Product{tt.product},
+				canonicalProducts: []entity.CanonicalProduct{tt.canonicalProduct},
+			}
+			if tt.matchedRetailer != "" {
+				d.matches = []entity.ProductMatch{{
+					ProductID:          "other",
+					CanonicalProductID: tt.canonicalProduct.ID,
+					RetailerID:         tt.matchedRetailer,
+				}}
+			}
+			existingMatches := len(d.matches)
+
+			if err := NewMatchProductsUseCase(d).Execute(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(d.matches) != existingMatches+1 {
+				t.Fatalf("got %d new matches, want 1", len(d.matches)-existingMatches)
+			}
+			match := d.matches[existingMatches]
+
+			matched := match.CanonicalProductID == tt.canonicalProduct.ID
+			if matched != tt.wantMatched ||
+				match.Method != string(tt.wantMethod) ||
+				match.Status != string(tt.wantStatus) {
+				t.Errorf(
+					"got a %s %s match to %s with confidence %v, "+
+						"want a %s %s match, to the canonical product: %v",
+					match.Status, match.Method, match.CanonicalProductID,
+					match.Confidence, tt.wantStatus, tt.wantMethod,
+					tt.wantMatched,
+				)
+			}
+		})
+	}
+}
+
+func TestMatchConfidence(t *testing.T) {
+	rice := entity.CanonicalProduct{
+		Name:      "Arroz Branco Tio João 5kg",
+		Brand:     ptr("Tio João"),
+		Quantity:  ptr(5.0),
+		Unit:      ptr(string(normalize.UnitKilogram)),
+		PackCount: ptr(1),
+	}
+	soda := entity.CanonicalProduct{
+		Name:      "Refrigerante Coca-Cola Lata",
+		Brand:     ptr("Coca-Cola"),
+		Quantity:  ptr(2.1),
+		Unit:      ptr(string(normalize.UnitLiter)),
+		PackCount: ptr(1),
+	}
+
+	tests := []struct {
+		name             string
+		canonicalProduct entity.CanonicalProduct
+		product          entity.Product
+		want             float64
+	}{
+		{
+			name:             "same name, brand and size",
+			canonicalProduct: rice,
+			product:          productOf(rice),
+			want:             1,
+		},
+		{
+			name:             "same size in other units and packs",
+			canonicalProduct: soda,
+			product: entity.Product{
+				Name:      "REFRIGERANTE COCA COLA LATA",
+				Brand:     ptr("coca-cola"),
+				Quantity:  ptr(350.0),
+				Unit:      ptr(string(normalize.UnitMilliliter)),
+				PackCount: ptr(6),
+			},
+			want: 1,
+		},
+		{
+			name:             "brand of one side only counts half",
+			canonicalProduct: rice,
+			product: func() entity.Product {
+				product := productOf(rice)
+				product.Brand = nil
+				return product
+			}(),
+			want: 0.6 + 0.2*0.5 + 0.2,
+		},
+		{
+			name:             "different brand",
+			canonicalProduct: rice,
+			product: func() entity.Product {
+				product := productOf(rice)
+				product.Brand = ptr("Camil")
+				return product
+			}(),
+			want: 0.6 + 0.2,
+		},
+		{
+			name:             "size of one side only counts half",
+			canonicalProduct: rice,
+			product: func() entity.Product {
+				product := productOf(rice)
+				product.Quantity, product.Unit = nil, nil
+				return product
+			}(),
+			want: 0.6 + 0.2 + 0.2*0.5,
+		},
+		{
+			name:             "different size",
+			canonicalProduct: rice,
+			product: func() entity.Product {
+				product := productOf(rice)
+				product.Quantity = ptr(1.0)
+				return product
+			}(),
+			want: 0,
+		},
+		{
+			name:             "similar name",
+			canonicalProduct: rice,
+			product: func() entity.Product {
+				product := productOf(rice)
+				product.Name = "Arroz Tio João Tipo 1"
+				return product
+			}(),
+			want: 0.6*normalize.Similarity(
+				"Arroz Tio João Tipo 1",
+				rice.Name,
+			) + 0.2 + 0.2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := matchConfidence(
+				tt.product,
+				normalize.Trigrams(tt.product.Name),
+				newMatchCandidate(tt.canonicalProduct),
+			)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("matchConfidence() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func productOf(canonicalProduct entity.CanonicalProduct) entity.Product {
+	return entity.Product{
+		Name:      canonicalProduct.Name,
+		Brand:     canonicalProduct.Brand,
+		Quantity:  canonicalProduct.Quantity,
+		Unit:      canonicalProduct.Unit,
+		PackCount: canonicalProduct.PackCount,
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
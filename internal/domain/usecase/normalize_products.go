@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/normalize"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+type NormalizeProductsUseCase struct {
+	db db.DB
+}
+
+func NewNormalizeProductsUseCase(db db.DB) *NormalizeProductsUseCase {
+	return &NormalizeProductsUseCase{
+		db: db,
+	}
+}
+
+// Execute recomputes the normalized name, measures and guessed brand of
+// every product of the retailer from its name, updating the products they
+// changed for. Migrations can only approximate normalize.Name in SQL, and
+// older versions parsed names differently, so products are identified by
+// name consistently only once this ran. It rewrites the whole catalog of the
+// retailer, so it is run on demand, through the matcher's -normalize flag,
+// rather than by every scrape run.
+func (u *NormalizeProductsUseCase) Execute(
+	ctx context.Context,
+	retailerID string,
+) error {
+	products, err := u.db.ListProducts(ctx, retailerID)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	changedProducts := []entity.Product{}
+	for _, product := range products {
+		normalized := product
+		normalized.NormalizedName = normalize.Name(product.Name)
+		setMeasures(&normalized)
+
+		if normalized.NormalizedName == product.NormalizedName &&
+			equal(normalized.ParsedBrand, product.ParsedBrand) &&
+			equal(normalized.Quantity, product.Quantity) &&
+			equal(normalized.Unit, product.Unit) &&
+			equal(normalized.PackCount, product.PackCount) {
+			continue
+		}
+		changedProducts = append(changedProducts, normalized)
+	}
+
+	if err := u.db.UpdateProducts(ctx, changedProducts); err != nil {
+		return errs.New(err)
+	}
+
+	if len(changedProducts) > 0 {
+		slog.Info(
+			"products normalized",
+			"retailer_id", retailerID,
+			"products", len(changedProducts),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package usecase_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/usecase"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/normalize"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqldb"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+)
+
+// TestNormalizeProducts checks the products whose normalized names were
+// backfilled by the migration end up normalized exactly as normalize.Name
+// does, which the SQL of the migration only approximates.
+func TestNormalizeProducts(t *testing.T) {
+	const backfill = "20250616120000_identify_products_by_code"
+
+	tests := []struct {
+		name string
+		// sqlMatches is whether the backfill of the migration already
+		// normalizes the name as normalize.Name does.
+		sqlMatches bool
+	}{
+		{name: "Feijão Carioca Camil 1kg", sqlMatches: true},
+		{name: "AÇÚCAR REFINADO UNIÃO 1KG", sqlMatches: true},
+		{name: " Café 3 Corações 500g ", sqlMatches: true},
+		{name: "Crème Brûlée Nestlé", sqlMatches: true},
+		{name: "Arroz  Tio João\t5kg"},
+		{name: "Leite\u00a0Integral Italac 1L"},
+	}
+
+	path := filepath.Join(t.TempDir(), "test.db")
+	d, err := sqldb.Open(sqlite.Dialect, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer d.Close()
+
+	conn, err := sql.Open(sqlite.Dialect.Driver, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	migrations, err := filepath.Glob(
+		filepath.Join("../../../sql/migrations", "*", "migration.sql"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The products are inserted right before the migration backfills their
+	// normalized names.
+	for _, migration := range migrations {
+		if filepath.Base(filepath.Dir(migration)) == backfill {
+			for i, tt := range tests {
+				_, err := conn.Exec(
+					`INSERT INTO "products" ("id", "name", "price") VALUES (?, ?, 1)`,
+					strings.Repeat("0", i+1),
+					tt.name,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+
+		query, err := os.ReadFile(migration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec(string(query)); err != nil {
+			t.Fatalf("failed to apply %s: %v", migration, err)
+		}
+	}
+
+	ctx := context.Background()
+	const retailerID = "atacadao"
+
+	products, err := d.ListProducts(ctx, retailerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != len(tests) {
+		t.Fatalf("got %d products, want %d", len(products), len(tests))
+	}
+
+	sqlMatches := map[string]bool{}
+	for _, tt := range tests {
+		sqlMatches[tt.name] = tt.sqlMatches
+	}
+	for _, product := range products {
+		want := normalize.Name(product.Name)
+		if got := product.NormalizedName == want; got != sqlMatches[product.Name] {
+			t.Errorf(
+				"migration normalized %q as %q, normalize.Name as %q",
+				product.Name, product.NormalizedName, want,
+			)
+		}
+	}
+
+	err = usecase.NewNormalizeProductsUseCase(d).Execute(ctx, retailerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	products, err = d.ListProducts(ctx, retailerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, product := range products {
+		if want := normalize.Name(product.Name); product.NormalizedName != want {
+			t.Errorf(
+				"got %q normalized as %q, want %q",
+				product.Name, product.NormalizedName, want,
+			)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+type SaveRawPayloadUseCase struct {
+	db db.DB
+}
+
+func NewSaveRawPayloadUseCase(db db.DB) *SaveRawPayloadUseCase {
+	return &SaveRawPayloadUseCase{
+		db: db,
+	}
+}
+
+// Execute stores the raw body of a page of the run, gzip compressed, so
+// products can later be rebuilt from it without crawling it again.
+func (u *SaveRawPayloadUseCase) Execute(
+	ctx context.Context,
+	payload entity.RawPayload,
+) error {
+	buf := bytes.Buffer{}
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(payload.Body); err != nil {
+		return errs.New(err)
+	}
+	if err := w.Close(); err != nil {
+		return errs.New(err)
+	}
+	payload.Body = buf.Bytes()
+
+	if err := u.db.CreateRawPayload(ctx, payload); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+type ListRawPayloadsUseCase struct {
+	db db.DB
+}
+
+func NewListRawPayloadsUseCase(db db.DB) *ListRawPayloadsUseCase {
+	return &ListRawPayloadsUseCase{
+		db: db,
+	}
+}
+
+// Execute calls fn with each raw body stored for the pages of the run,
+// decompressed. Payloads are loaded a batch at a time, so a whole run is
+// never held in memory.
+func (u *ListRawPayloadsUseCase) Execute(
+	ctx context.Context,
+	runID string,
+	fn func(payload entity.RawPayload) error,
+) error {
+	const batchSize = 50
+
+	for page := 1; ; page++ {
+		payloads, err := u.db.ListRawPayloads(ctx, runID, db.Pagination{
+			Page:     page,
+			PageSize: batchSize,
+		})
+		if err != nil {
+			return errs.New(err)
+		}
+
+		for _, payload := range payloads {
+			r, err := gzip.NewReader(bytes.NewReader(payload.Body))
+			if err != nil {
+				return errs.New(err)
+			}
+
+			payload.Body, err = io.ReadAll(r)
+			if err != nil {
+				return errs.New(err)
+			}
+
+			if err := fn(payload); err != nil {
+				return errs.New(err)
+			}
+		}
+
+		if len(payloads) < batchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+// UncertainMatch is a match waiting to be reviewed, along with the product
+// and the canonical product it links.
+type UncertainMatch struct {
+	Match            entity.ProductMatch
+	Product          entity.Product
+	CanonicalProduct entity.CanonicalProduct
+}
+
+type ListUncertainMatchesUseCase struct {
+	db db.DB
+}
+
+func NewListUncertainMatchesUseCase(db db.DB) *ListUncertainMatchesUseCase {
+	return &ListUncertainMatchesUseCase{
+		db: db,
+	}
+}
+
+// Execute lists the matches waiting to be reviewed, the least confident
+// first.
+func (u *ListUncertainMatchesUseCase) Execute(
+	ctx context.Context,
+) ([]UncertainMatch, error) {
+	matches, err := listPendingMatches(ctx, u.db, nil)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+	if len(matches) == 0 {
+		return nil, nil
+	}
+
+	productIDs := make([]string, len(matches))
+	for i, match := range matches {
+		productIDs[i] = match.ProductID
+	}
+	products, err := u.db.ListProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+	productsByID := map[string]entity.Product{}
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+
+	canonicalProducts, err := u.db.ListCanonicalProducts(ctx)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+	canonicalProductsByID := map[string]entity.CanonicalProduct{}
+	for _, canonicalProduct := range canonicalProducts {
+		canonicalProductsByID[canonicalProduct.ID] = canonicalProduct
+	}
+
+	uncertainMatches := make([]UncertainMatch, len(matches))
+	for i, match := range matches {
+		uncertainMatches[i] = UncertainMatch{
+			Match:            match,
+			Product:          productsByID[match.ProductID],
+			CanonicalProduct: canonicalProductsByID[match.CanonicalProductID],
+		}
+	}
+
+	return uncertainMatches, nil
+}
+
+type ConfirmMatchesUseCase struct {
+	db db.DB
+}
+
+func NewConfirmMatchesUseCase(db db.DB) *ConfirmMatchesUseCase {
+	return &ConfirmMatchesUseCase{
+		db: db,
+	}
+}
+
+// Execute confirms the pending matches, the products being the same items
+// as their canonical products.
+func (u *ConfirmMatchesUseCase) Execute(
+	ctx context.Context,
+	ids []string,
+) error {
+	matches, err := listPendingMatches(ctx, u.db, ids)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	for i := range matches {
+		matches[i].Status = string(entity.MatchStatusConfirmed)
+	}
+
+	if err := u.db.UpdateProductMatches(ctx, matches); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+type RejectMatchesUseCase struct {
+	db db.DB
+}
+
+func NewRejectMatchesUseCase(db db.DB) *RejectMatchesUseCase {
+	return &RejectMatchesUseCase{
+		db: db,
+	}
+}
+
+// Execute rejects the pending matches, each product becoming a canonical
+// product of its own.
+func (u *RejectMatchesUseCase) Execute(
+	ctx context.Context,
+	ids []string,
+) error {
+	matches, err := listPendingMatches(ctx, u.db, ids)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	productIDs := make([]string, len(matches))
+	for i, match := range matches {
+		productIDs[i] = match.ProductID
+	}
+	products, err := u.db.ListProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return errs.New(err)
+	}
+	productsByID := map[string]entity.Product{}
+	for _, product := range products {
+		productsByID[product.ID] = product
+	}
+
+	canonicalProducts := make([]entity.CanonicalProduct, len(matches))
+	for i, match := range matches {
+		canonicalProducts[i] = newCanonicalProduct(
+			productsByID[match.ProductID],
+		)
+	}
+	err = u.db.CreateCanonicalProducts(ctx, canonicalProducts)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	for i := range matches {
+		matches[i].CanonicalProductID = canonicalProducts[i].ID
+		matches[i].Method = string(entity.MatchMethodManual)
+		matches[i].Status = string(entity.MatchStatusConfirmed)
+		matches[i].Confidence = 1
+	}
+
+	if err := u.db.UpdateProductMatches(ctx, matches); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// listPendingMatches lists the pending matches with the given IDs, or all of
+// them when ids is nil, the least confident first. Every given ID must be of
+// a pending match.
+func listPendingMatches(
+	ctx context.Context,
+	db db.DB,
+	ids []string,
+) ([]entity.ProductMatch, error) {
+	matches, err := db.ListProductMatches(ctx)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	wanted := map[string]bool{}
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	pendingMatches := []entity.ProductMatch{}
+	for _, match := range matches {
+		if match.Status != string(entity.MatchStatusPending) {
+			continue
+		}
+		if ids != nil && !wanted[match.ID] {
+			continue
+		}
+		delete(wanted, match.ID)
+		pendingMatches = append(pendingMatches, match)
+	}
+
+	if len(wanted) > 0 {
+		notPending := slices.Sorted(maps.Keys(wanted))
+		return nil, errs.New(
+			fmt.Sprintf(
+				"matches not pending: %s",
+				strings.Join(notPending, ", "),
+			),
+		)
+	}
+
+	slices.SortStableFunc(pendingMatches, func(a, b entity.ProductMatch) int {
+		return cmp.Compare(a.Confidence, b.Confidence)
+	})
+
+	return pendingMatches, nil
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+const (
+	// healthCheckMinProducts is how many products a category needs in both
+	// runs for their shares of missing fields to be compared.
+	healthCheckMinProducts = 10
+	// missingFieldsSpikeThreshold is how much the share of the products of a
+	// category missing a field must grow from the previous run to be
+	// flagged, e.g. from 5% to 30%.
+	missingFieldsSpikeThreshold = 0.25
+)
+
+// MissingFieldsSpike is a field that many more products of a category missed
+// than in the previous run, which usually means a selector broke.
+type MissingFieldsSpike struct {
+	Category string
+	Field    string
+	// Share and PreviousShare are the shares of the products of the category
+	// missing the field, from 0 to 1.
+	Share         float64
+	PreviousShare float64
+	PreviousRunID string
+}
+
+type CheckRunHealthUseCase struct {
+	db   db.DB
+	seuc *SaveErrorUseCase
+}
+
+func NewCheckRunHealthUseCase(
+	db db.DB,
+	seuc *SaveErrorUseCase,
+) *CheckRunHealthUseCase {
+	return &CheckRunHealthUseCase{
+		db:   db,
+		seuc: seuc,
+	}
+}
+
+// Execute compares the fields the products of each category missed during
+// the finished run with the previous crawl of the same source and store that
+// did not fail, and logs and records every spike as an error of the run, so
+// that a broken scraper is noticed even when the run itself succeeds. Failed
+// runs and retries are not checked.
+func (u *CheckRunHealthUseCase) Execute(
+	ctx context.Context,
+	tracker *RunTracker,
+) error {
+	spikes, err := u.spikes(ctx, tracker)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	for _, spike := range spikes {
+		slog.Warn(
+			"products missing a field spiked",
+			"run_id", tracker.ID,
+			"category", spike.Category,
+			"field", spike.Field,
+			"share", spike.Share,
+			"previous_share", spike.PreviousShare,
+		)
+		_ = u.seuc.Execute(
+			ctx,
+			tracker.ID,
+			errs.New(
+				fmt.Sprintf(
+					"%.0f%% of the products of %s miss %s, up from %.0f%%",
+					spike.Share*100,
+					spike.Category,
+					spike.Field,
+					spike.PreviousShare*100,
+				),
+				errs.ErrTypeMissingFieldsSpike,
+			),
+			map[string]any{
+				"retailer_id":     tracker.RetailerID,
+				"category":        spike.Category,
+				"field":           spike.Field,
+				"share":           spike.Share,
+				"previous_share":  spike.PreviousShare,
+				"previous_run_id": spike.PreviousRunID,
+			},
+		)
+	}
+
+	return nil
+}
+
+// spikes returns the spikes of the run, sorted by category and field.
+func (u *CheckRunHealthUseCase) spikes(
+	ctx context.Context,
+	tracker *RunTracker,
+) ([]MissingFieldsSpike, error) {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	if tracker.Status == string(entity.RunStatusFailed) ||
+		tracker.Kind == string(entity.RunKindRetry) {
+		return nil, nil
+	}
+
+	previous, err := u.previousRun(ctx, tracker.ScrapeRun)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+	if previous == nil {
+		return nil, nil
+	}
+
+	previousCategories := map[string]*CategoryCount{}
+	err = json.Unmarshal([]byte(previous.CategoryCounts), &previousCategories)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	spikes := []MissingFieldsSpike{}
+	for category, count := range tracker.categories {
+		previousCount, ok := previousCategories[category]
+		if !ok ||
+			count.Products < healthCheckMinProducts ||
+			previousCount.Products < healthCheckMinProducts {
+			continue
+		}
+
+		for field, missing := range count.Missing {
+			share := float64(missing) / float64(count.Products)
+			previousShare := float64(previousCount.Missing[field]) /
+				float64(previousCount.Products)
+			if share-previousShare < missingFieldsSpikeThreshold {
+				continue
+			}
+
+			spikes = append(spikes, MissingFieldsSpike{
+				Category:      category,
+				Field:         field,
+				Share:         share,
+				PreviousShare: previousShare,
+				PreviousRunID: previous.ID,
+			})
+		}
+	}
+
+	slices.SortFunc(spikes, func(a, b MissingFieldsSpike) int {
+		return cmp.Or(
+			cmp.Compare(a.Category, b.Category),
+			cmp.Compare(a.Field, b.Field),
+		)
+	})
+
+	return spikes, nil
+}
+
+// previousRun returns the latest crawl of the same source and store that was
+// started before the run and did not fail, or nil if there is none. Retries
+// are left out, as they only count the products of the pages they request.
+func (u *CheckRunHealthUseCase) previousRun(
+	ctx context.Context,
+	run entity.ScrapeRun,
+) (*entity.ScrapeRun, error) {
+	runs, err := u.db.ListScrapeRuns(ctx, run.RetailerID)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var previous *entity.ScrapeRun
+	for _, r := range runs {
+		if r.ID == run.ID {
+			break
+		}
+		if r.Source == run.Source &&
+			r.Kind != string(entity.RunKindRetry) &&
+			equal(r.StoreID, run.StoreID) &&
+			(r.Status == string(entity.RunStatusSucceeded) ||
+				r.Status == string(entity.RunStatusPartial)) {
+			previous = &r
+		}
+	}
+
+	return previous, nil
+}
+
+// missingFields lists the fields the product has empty, out of those the
+// scrapers fill. A source may never fill some of them, which is not a spike
+// as long as it keeps not filling them.
+func missingFields(product entity.Product) []string {
+	fields := []string{}
+	if strings.TrimSpace(product.Name) == "" {
+		fields = append(fields, "name")
+	}
+	if product.Price <= 0 {
+		fields = append(fields, "price")
+	}
+	if product.ListPrice == nil {
+		fields = append(fields, "list_price")
+	}
+	if product.WholesalePrice != nil && product.WholesaleMinQuantity == nil {
+		fields = append(fields, "wholesale_min_quantity")
+	}
+	if blank(product.Brand) {
+		fields = append(fields, "brand")
+	}
+	if blank(product.Code) {
+		fields = append(fields, "code")
+	}
+	if blank(product.URL) {
+		fields = append(fields, "url")
+	}
+	if blank(product.ImageURL) {
+		fields = append(fields, "image_url")
+	}
+
+	return fields
+}
+
+func blank(s *string) bool {
+	return s == nil || strings.TrimSpace(*s) == ""
+}
@@ -4,23 +4,23 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"time"
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
 )
 
 type SaveErrorUseCase struct {
-	db *sqlite.DB
+	db db.DB
 }
 
-func NewSaveErrorUseCase(db *sqlite.DB) *SaveErrorUseCase {
+func NewSaveErrorUseCase(db db.DB) *SaveErrorUseCase {
 	return &SaveErrorUseCase{db: db}
 }
 
 func (u *SaveErrorUseCase) Execute(
 	ctx context.Context,
+	runID string,
 	err error,
 	metadata map[string]any,
 ) error {
@@ -28,13 +28,13 @@ func (u *SaveErrorUseCase) Execute(
 		return nil
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
 	entityErr := entity.Error{
 		Message: err.Error(),
 		Type:    string(errs.ErrTypeUnknown),
 	}
+	if runID != "" {
+		entityErr.RunID = &runID
+	}
 
 	var appErr *errs.Err
 	if errors.As(err, &appErr) {
@@ -5,53 +5,351 @@ import (
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/normalize"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
 )
 
 type SaveProductsUseCase struct {
-	db *sqlite.DB
+	db db.DB
 }
 
-func NewSaveProductsUseCase(db *sqlite.DB) *SaveProductsUseCase {
+func NewSaveProductsUseCase(db db.DB) *SaveProductsUseCase {
 	return &SaveProductsUseCase{
 		db: db,
 	}
 }
 
+// Execute keeps the products catalog up to date and records a price
+// observation for every scraped product, with all of its price tiers, so
+// price changes are never lost.
+// Observations are tagged with the run, its source and the store it crawls.
+//
+// A product is identified by its retailer and code, falling back to its
+// normalized name when the retailer does not provide a code.
+//
+// Prices and availability vary by store, so those scraped for a store are
+// only recorded on its observations, which also become the current prices
+// of the product at the store. Products keep the ones they were first
+// listed with, updated only by runs that crawl no particular store.
+//
+// Delisted products that are scraped again are relisted. When run is nil, as
+// when products are rebuilt from stored payloads, which may be older than the
+// catalog, only the attributes the products lack are filled in: no product
+// is created or relisted and no observation is recorded.
+//
+// The recorded observations are returned.
 func (u *SaveProductsUseCase) Execute(
 	ctx context.Context,
+	run *RunTracker,
 	products []entity.Product,
-) error {
+) ([]entity.PriceObservation, error) {
 	if len(products) == 0 {
-		return nil
+		return nil, nil
 	}
 
-	names := []string{}
-	for _, product := range products {
-		names = append(names, product.Name)
-	}
+	codesByRetailer := map[string][]string{}
+	namesByRetailer := map[string][]string{}
+	for i := range products {
+		if products[i].Code != nil && *products[i].Code == "" {
+			products[i].Code = nil
+		}
+		products[i].NormalizedName = normalize.Name(products[i].Name)
+		setMeasures(&products[i])
 
-	existingProducts, err := u.db.ListProductsByNames(ctx, names)
-	if err != nil {
-		return errs.New(err)
+		retailerID := products[i].RetailerID
+		if products[i].Code != nil {
+			codesByRetailer[retailerID] = append(
+				codesByRetailer[retailerID],
+				*products[i].Code,
+			)
+			continue
+		}
+		namesByRetailer[retailerID] = append(
+			namesByRetailer[retailerID],
+			products[i].NormalizedName,
+		)
 	}
 
-	existingProductsByName := map[string]entity.Product{}
-	for _, product := range existingProducts {
-		existingProductsByName[product.Name] = product
+	productsByKey := map[string]entity.Product{}
+	for retailerID, codes := range codesByRetailer {
+		existingProducts, err := u.db.ListProductsByCodes(
+			ctx,
+			retailerID,
+			codes,
+		)
+		if err != nil {
+			return nil, errs.New(err)
+		}
+		for _, product := range existingProducts {
+			productsByKey[productKey(product)] = product
+		}
+	}
+	for retailerID, names := range namesByRetailer {
+		existingProducts, err := u.db.ListProductsByNormalizedNames(
+			ctx,
+			retailerID,
+			names,
+		)
+		if err != nil {
+			return nil, errs.New(err)
+		}
+		for _, product := range existingProducts {
+			if product.Code != nil {
+				continue
+			}
+			// A listed product wins over a delisted one of the same name.
+			existing, ok := productsByKey[productKey(product)]
+			if ok && existing.DeletedAt == nil {
+				continue
+			}
+			productsByKey[productKey(product)] = product
+		}
 	}
 
+	updatePrices := run != nil && run.StoreID == nil
+
 	productsToCreate := []entity.Product{}
+	productsToUpdate := map[string]entity.Product{}
+	productsToRelist := []string{}
 	for _, product := range products {
-		if _, ok := existingProductsByName[product.Name]; ok {
+		key := productKey(product)
+
+		existingProduct, ok := productsByKey[key]
+		if run == nil {
+			if ok && existingProduct.ID != "" &&
+				fillMissing(&existingProduct, product) {
+				productsToUpdate[existingProduct.ID] = existingProduct
+				productsByKey[key] = existingProduct
+			}
+			continue
+		}
+		if !ok {
+			productsToCreate = append(productsToCreate, product)
+			productsByKey[key] = product
+			continue
+		}
+		if existingProduct.DeletedAt != nil {
+			productsToRelist = append(productsToRelist, existingProduct.ID)
+			existingProduct.DeletedAt = nil
+			productsByKey[key] = existingProduct
+		}
+		if existingProduct.ID == "" ||
+			!productChanged(existingProduct, product, updatePrices) {
 			continue
 		}
-		productsToCreate = append(productsToCreate, product)
+
+		existingProduct.Name = product.Name
+		existingProduct.NormalizedName = product.NormalizedName
+		if updatePrices {
+			existingProduct.Price = product.Price
+			existingProduct.ListPrice = product.ListPrice
+			existingProduct.PromoPrice = product.PromoPrice
+			existingProduct.WholesalePrice = product.WholesalePrice
+			existingProduct.WholesaleMinQuantity = product.WholesaleMinQuantity
+			setIfNotNil(&existingProduct.Available, product.Available)
+		}
+		existingProduct.Brand = product.Brand
+		existingProduct.ParsedBrand = product.ParsedBrand
+		existingProduct.Quantity = product.Quantity
+		existingProduct.Unit = product.Unit
+		existingProduct.PackCount = product.PackCount
+		setIfNotNil(&existingProduct.GTIN, product.GTIN)
+		setIfNotNil(&existingProduct.Slug, product.Slug)
+		setIfNotNil(&existingProduct.URL, product.URL)
+		setIfNotNil(&existingProduct.ImageURL, product.ImageURL)
+		setIfNotNil(&existingProduct.Breadcrumb, product.Breadcrumb)
+		setIfNotNil(&existingProduct.Seller, product.Seller)
+		setIfNotNil(&existingProduct.CategoryID, product.CategoryID)
+		productsToUpdate[existingProduct.ID] = existingProduct
+		productsByKey[key] = existingProduct
 	}
 
 	if err := u.db.CreateProducts(ctx, productsToCreate); err != nil {
-		return errs.New(err)
+		return nil, errs.New(err)
+	}
+	for _, product := range productsToCreate {
+		productsByKey[productKey(product)] = product
+	}
+
+	updatedProducts := make([]entity.Product, 0, len(productsToUpdate))
+	for _, product := range productsToUpdate {
+		updatedProducts = append(updatedProducts, product)
+	}
+	if err := u.db.UpdateProducts(ctx, updatedProducts); err != nil {
+		return nil, errs.New(err)
+	}
+
+	if run == nil {
+		return nil, nil
+	}
+
+	err := u.db.ChangeProductListings(
+		ctx,
+		productsToRelist,
+		&run.ID,
+		entity.ListingStatusRelisted,
+	)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	observations := make([]entity.PriceObservation, len(products))
+	for i, product := range products {
+		observations[i] = entity.PriceObservation{
+			ProductID:            productsByKey[productKey(product)].ID,
+			Price:                product.Price,
+			ListPrice:            product.ListPrice,
+			PromoPrice:           product.PromoPrice,
+			WholesalePrice:       product.WholesalePrice,
+			WholesaleMinQuantity: product.WholesaleMinQuantity,
+			Available:            product.Available,
+			Source:               run.Source,
+			RunID:                &run.ID,
+			StoreID:              run.StoreID,
+		}
+		setUnitPrice(&observations[i], product)
+	}
+
+	if err := u.db.CreatePriceObservations(ctx, observations); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return observations, nil
+}
+
+// setMeasures parses the quantity, unit and pack count of the product from
+// its name, along with a guess of its brand. The guess is kept apart from
+// the brand, which only the retailer provides.
+func setMeasures(product *entity.Product) {
+	attributes := normalize.Parse(product.Name)
+
+	product.ParsedBrand = nil
+	if attributes.Brand != "" {
+		product.ParsedBrand = &attributes.Brand
+	}
+
+	if attributes.Unit == "" {
+		product.Quantity, product.Unit, product.PackCount = nil, nil, nil
+		return
+	}
+	unit := string(attributes.Unit)
+	product.Quantity = &attributes.Quantity
+	product.Unit = &unit
+	product.PackCount = &attributes.PackCount
+}
+
+// setUnitPrice sets the price per kg, L or unit of the observation, so the
+// value of different pack sizes can be compared.
+func setUnitPrice(
+	observation *entity.PriceObservation,
+	product entity.Product,
+) {
+	if product.Quantity == nil || product.Unit == nil {
+		return
 	}
 
-	return nil
+	packCount := 1
+	if product.PackCount != nil {
+		packCount = *product.PackCount
+	}
+
+	unitPrice, pricedBy, ok := normalize.UnitPrice(
+		observation.Price,
+		*product.Quantity,
+		normalize.Unit(*product.Unit),
+		packCount,
+	)
+	if !ok {
+		return
+	}
+
+	unit := string(pricedBy)
+	observation.UnitPrice = &unitPrice
+	observation.UnitPriceUnit = &unit
+}
+
+func productKey(product entity.Product) string {
+	if product.Code != nil {
+		return product.RetailerID + ":code:" + *product.Code
+	}
+	return product.RetailerID + ":name:" + product.NormalizedName
+}
+
+// productChanged reports whether the scraped product differs from the
+// existing one, comparing prices and availability only when withPrices.
+func productChanged(existing, scraped entity.Product, withPrices bool) bool {
+	if existing.Name != scraped.Name {
+		return true
+	}
+	if withPrices && (existing.Price != scraped.Price ||
+		!equal(existing.ListPrice, scraped.ListPrice) ||
+		!equal(existing.PromoPrice, scraped.PromoPrice) ||
+		!equal(existing.WholesalePrice, scraped.WholesalePrice) ||
+		!equal(existing.WholesaleMinQuantity, scraped.WholesaleMinQuantity) ||
+		changedIfNotNil(existing.Available, scraped.Available)) {
+		return true
+	}
+	if !equal(existing.Brand, scraped.Brand) ||
+		!equal(existing.ParsedBrand, scraped.ParsedBrand) ||
+		!equal(existing.Quantity, scraped.Quantity) ||
+		!equal(existing.Unit, scraped.Unit) ||
+		!equal(existing.PackCount, scraped.PackCount) {
+		return true
+	}
+
+	// A product scraped without some of the attributes only a retailer API
+	// provides keeps the ones it had.
+	return changedIfNotNil(existing.GTIN, scraped.GTIN) ||
+		changedIfNotNil(existing.Slug, scraped.Slug) ||
+		changedIfNotNil(existing.URL, scraped.URL) ||
+		changedIfNotNil(existing.ImageURL, scraped.ImageURL) ||
+		changedIfNotNil(existing.Breadcrumb, scraped.Breadcrumb) ||
+		changedIfNotNil(existing.Seller, scraped.Seller) ||
+		changedIfNotNil(existing.CategoryID, scraped.CategoryID)
+}
+
+// equal reports whether both values are nil or point to equal values.
+func equal[T comparable](a, b *T) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// changedIfNotNil reports whether the scraped value is set and differs from
+// the existing one.
+func changedIfNotNil[T comparable](existing, scraped *T) bool {
+	return scraped != nil && !equal(existing, scraped)
+}
+
+// setIfNotNil sets dst to src, unless src is nil.
+// fillMissing sets the attributes of the existing product that it lacks and
+// the scraped one has, reporting whether any was set. Prices and
+// availability are left out, as they only hold when they were scraped.
+func fillMissing(existing *entity.Product, scraped entity.Product) bool {
+	filled := setIfNil(&existing.Brand, scraped.Brand)
+	filled = setIfNil(&existing.GTIN, scraped.GTIN) || filled
+	filled = setIfNil(&existing.Slug, scraped.Slug) || filled
+	filled = setIfNil(&existing.URL, scraped.URL) || filled
+	filled = setIfNil(&existing.ImageURL, scraped.ImageURL) || filled
+	filled = setIfNil(&existing.Breadcrumb, scraped.Breadcrumb) || filled
+	filled = setIfNil(&existing.Seller, scraped.Seller) || filled
+	filled = setIfNil(&existing.CategoryID, scraped.CategoryID) || filled
+	return filled
+}
+
+// setIfNil sets dst to src when dst is nil and src is not, reporting whether
+// it did.
+func setIfNil[T any](dst **T, src *T) bool {
+	if *dst != nil || src == nil {
+		return false
+	}
+	*dst = src
+	return true
+}
+
+func setIfNotNil[T any](dst **T, src *T) {
+	if src != nil {
+		*dst = src
+	}
 }
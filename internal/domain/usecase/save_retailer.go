@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+type SaveRetailerUseCase struct {
+	db db.DB
+}
+
+func NewSaveRetailerUseCase(db db.DB) *SaveRetailerUseCase {
+	return &SaveRetailerUseCase{
+		db: db,
+	}
+}
+
+// Execute registers the retailer, so its products can reference it.
+func (u *SaveRetailerUseCase) Execute(
+	ctx context.Context,
+	retailer entity.Retailer,
+) error {
+	if err := u.db.UpsertRetailer(ctx, retailer); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+// RetryOutcome is the result of retrying a stored error. Err is nil when
+// the retry succeeded.
+type RetryOutcome struct {
+	Error entity.Error
+	Err   error
+}
+
+type SaveRetryOutcomesUseCase struct {
+	e  *env.Env
+	db db.DB
+}
+
+func NewSaveRetryOutcomesUseCase(
+	e *env.Env,
+	db db.DB,
+) *SaveRetryOutcomesUseCase {
+	return &SaveRetryOutcomesUseCase{
+		e:  e,
+		db: db,
+	}
+}
+
+// Execute soft-deletes the errors whose retry succeeded and counts an
+// attempt for the others, which are marked as permanently failed, and no
+// longer retried, once they reach the maximum number of attempts.
+func (u *SaveRetryOutcomesUseCase) Execute(
+	ctx context.Context,
+	outcomes []RetryOutcome,
+) error {
+	now := time.Now()
+	succeededIDs := []string{}
+	failedErrors := []entity.Error{}
+	for _, outcome := range outcomes {
+		if outcome.Err == nil {
+			succeededIDs = append(succeededIDs, outcome.Error.ID)
+			continue
+		}
+
+		dbError := outcome.Error
+		failure := outcome.Err.Error()
+		dbError.Attempts++
+		dbError.LastFailure = &failure
+		dbError.LastAttemptAt = &now
+		if dbError.Attempts >= u.e.RetryMaxAttempts {
+			dbError.PermanentlyFailedAt = &now
+			slog.Warn(
+				"giving up on error",
+				"id", dbError.ID,
+				"type", dbError.Type,
+				"attempts", dbError.Attempts,
+				"last_failure", failure,
+			)
+		}
+		failedErrors = append(failedErrors, dbError)
+	}
+
+	if len(succeededIDs) > 0 {
+		if err := u.db.DeleteErrors(ctx, succeededIDs); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	if err := u.db.UpdateErrorAttempts(ctx, failedErrors); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
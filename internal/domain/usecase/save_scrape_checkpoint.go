@@ -0,0 +1,31 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+type SaveScrapeCheckpointUseCase struct {
+	db db.DB
+}
+
+func NewSaveScrapeCheckpointUseCase(db db.DB) *SaveScrapeCheckpointUseCase {
+	return &SaveScrapeCheckpointUseCase{
+		db: db,
+	}
+}
+
+// Execute marks a page of the run as done, so resuming the run skips it.
+func (u *SaveScrapeCheckpointUseCase) Execute(
+	ctx context.Context,
+	checkpoint entity.ScrapeCheckpoint,
+) error {
+	if err := u.db.CreateScrapeCheckpoint(ctx, checkpoint); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
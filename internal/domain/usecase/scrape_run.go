@@ -0,0 +1,284 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+)
+
+// CategoryCount holds how many products and errors a category produced
+// during a run, along with how many of its products missed each field.
+type CategoryCount struct {
+	Products int            `json:"products"`
+	Errors   int            `json:"errors"`
+	Missing  map[string]int `json:"missing,omitempty"`
+	// Empty is set when the retailer lists no products in the category,
+	// telling it apart from a category that failed or was not reached.
+	Empty bool `json:"empty,omitempty"`
+}
+
+// RunTracker is a scrape run in progress. It is safe for concurrent use.
+type RunTracker struct {
+	entity.ScrapeRun
+
+	mu          sync.Mutex
+	categories  map[string]*CategoryCount
+	categoryIDs map[string]string
+}
+
+// SetCategories sets the categories the run crawls, so the ID of each
+// can be looked up by its path.
+func (t *RunTracker) SetCategories(categories []entity.Category) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.categoryIDs = make(map[string]string, len(categories))
+	for _, category := range categories {
+		t.categoryIDs[category.Path] = category.ID
+	}
+}
+
+// CategoryID returns the ID of the category with the given path, or nil if
+// the run does not know it.
+func (t *RunTracker) CategoryID(path string) *string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id, ok := t.categoryIDs[path]
+	if !ok {
+		return nil
+	}
+	return &id
+}
+
+// AddProducts counts the products of the category, along with the fields
+// each of them misses.
+func (t *RunTracker) AddProducts(category string, products []entity.Product) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	count := t.category(category)
+	count.Products += len(products)
+	for _, product := range products {
+		for _, field := range missingFields(product) {
+			if count.Missing == nil {
+				count.Missing = map[string]int{}
+			}
+			count.Missing[field]++
+		}
+	}
+	t.ProductCount += len(products)
+}
+
+// MarkEmpty records that the retailer lists no products in the category.
+func (t *RunTracker) MarkEmpty(category string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.category(category).Empty = true
+}
+
+// AddError counts an error of the category, or of the whole run when
+// category is empty.
+func (t *RunTracker) AddError(category string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if category != "" {
+		t.category(category).Errors++
+	}
+	t.ErrorCount++
+}
+
+func (t *RunTracker) category(category string) *CategoryCount {
+	count, ok := t.categories[category]
+	if !ok {
+		count = &CategoryCount{}
+		t.categories[category] = count
+	}
+	return count
+}
+
+type StartScrapeRunUseCase struct {
+	db db.DB
+}
+
+func NewStartScrapeRunUseCase(db db.DB) *StartScrapeRunUseCase {
+	return &StartScrapeRunUseCase{
+		db: db,
+	}
+}
+
+type StartScrapeRunOptions struct {
+	RetailerID string
+	// StoreID is the store whose prices the run scrapes, if any.
+	StoreID *string
+	Source  entity.Source
+	Kind    entity.RunKind
+	// PageSize is the number of products per page the run requests, which
+	// its checkpoints count pages in, or 0 when it does not page.
+	PageSize int
+}
+
+func (u *StartScrapeRunUseCase) Execute(
+	ctx context.Context,
+	opts StartScrapeRunOptions,
+) (*RunTracker, error) {
+	run := entity.ScrapeRun{
+		RetailerID:     opts.RetailerID,
+		StoreID:        opts.StoreID,
+		Source:         string(opts.Source),
+		Kind:           string(opts.Kind),
+		Status:         string(entity.RunStatusRunning),
+		CategoryCounts: "{}",
+	}
+	if opts.PageSize > 0 {
+		run.PageSize = &opts.PageSize
+	}
+
+	if err := u.db.CreateScrapeRun(ctx, &run); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return &RunTracker{
+		ScrapeRun:  run,
+		categories: map[string]*CategoryCount{},
+	}, nil
+}
+
+type ResumeScrapeRunUseCase struct {
+	db db.DB
+}
+
+func NewResumeScrapeRunUseCase(db db.DB) *ResumeScrapeRunUseCase {
+	return &ResumeScrapeRunUseCase{
+		db: db,
+	}
+}
+
+// Execute reopens an unfinished run and returns it along with the pages it
+// already processed. Product counts carry over, while error counts restart,
+// since every page that failed is attempted again. The run keeps the page
+// size it began with, and takes pageSize only when it has none and no pages
+// processed.
+func (u *ResumeScrapeRunUseCase) Execute(
+	ctx context.Context,
+	runID string,
+	pageSize int,
+) (*RunTracker, []entity.ScrapeCheckpoint, error) {
+	run, err := u.db.GetScrapeRun(ctx, runID)
+	if err != nil {
+		return nil, nil, errs.New(err)
+	}
+	if run == nil {
+		return nil, nil, errs.New(
+			fmt.Sprintf("scrape run %s not found", runID),
+		)
+	}
+	if run.Status == string(entity.RunStatusSucceeded) {
+		return nil, nil, errs.New(
+			fmt.Sprintf("scrape run %s already succeeded", runID),
+		)
+	}
+	if run.Kind == string(entity.RunKindRetry) {
+		return nil, nil, errs.New(
+			fmt.Sprintf("scrape run %s is a retry, which cannot be resumed", runID),
+		)
+	}
+
+	checkpoints, err := u.db.ListScrapeCheckpoints(ctx, runID)
+	if err != nil {
+		return nil, nil, errs.New(err)
+	}
+
+	// Checkpoints count pages of the size the run began with, so they would
+	// skip or repeat products under any other size.
+	if run.PageSize == nil {
+		if len(checkpoints) > 0 {
+			return nil, nil, errs.New(
+				fmt.Sprintf(
+					"scrape run %s has no page size to resume with",
+					runID,
+				),
+			)
+		}
+		if pageSize > 0 {
+			run.PageSize = &pageSize
+		}
+	}
+
+	categories := map[string]*CategoryCount{}
+	err = json.Unmarshal([]byte(run.CategoryCounts), &categories)
+	if err != nil {
+		return nil, nil, errs.New(err)
+	}
+	for _, count := range categories {
+		count.Errors = 0
+	}
+
+	run.Kind = string(entity.RunKindResume)
+	run.Status = string(entity.RunStatusRunning)
+	run.ErrorCount = 0
+	run.FinishedAt = nil
+
+	if err := u.db.ReopenScrapeRun(ctx, *run); err != nil {
+		return nil, nil, errs.New(err)
+	}
+
+	return &RunTracker{
+		ScrapeRun:  *run,
+		categories: categories,
+	}, checkpoints, nil
+}
+
+type FinishScrapeRunUseCase struct {
+	db db.DB
+}
+
+func NewFinishScrapeRunUseCase(db db.DB) *FinishScrapeRunUseCase {
+	return &FinishScrapeRunUseCase{
+		db: db,
+	}
+}
+
+// Execute records the outcome of the run: failed when runErr is set,
+// partial when some errors were recorded along the way and
+// succeeded otherwise.
+func (u *FinishScrapeRunUseCase) Execute(
+	ctx context.Context,
+	tracker *RunTracker,
+	runErr error,
+) error {
+	tracker.mu.Lock()
+	defer tracker.mu.Unlock()
+
+	categoryCounts, err := json.Marshal(tracker.categories)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	run := tracker.ScrapeRun
+	run.CategoryCounts = string(categoryCounts)
+
+	switch {
+	case runErr != nil:
+		run.Status = string(entity.RunStatusFailed)
+	case run.ErrorCount > 0:
+		run.Status = string(entity.RunStatusPartial)
+	default:
+		run.Status = string(entity.RunStatusSucceeded)
+	}
+
+	if err := u.db.FinishScrapeRun(ctx, run); err != nil {
+		return errs.New(err)
+	}
+
+	tracker.ScrapeRun = run
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package usecase
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
+)
+
+type SyncCategoriesUseCase struct {
+	db db.DB
+}
+
+func NewSyncCategoriesUseCase(db db.DB) *SyncCategoriesUseCase {
+	return &SyncCategoriesUseCase{
+		db: db,
+	}
+}
+
+// Execute discovers the category tree of the retailer and stores it,
+// soft-deleting the categories the retailer no longer offers. If the tree
+// cannot be discovered, the categories stored by the last sync are used.
+//
+// The returned categories are sorted by path, so parents come before their
+// children.
+func (u *SyncCategoriesUseCase) Execute(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+) ([]entity.Category, error) {
+	retailerID := api.Retailer().ID
+
+	tree, err := api.ListCategories(ctx)
+	if err != nil {
+		categories, listErr := u.db.ListCategories(ctx, retailerID)
+		if listErr != nil || len(categories) == 0 {
+			return nil, errs.New(err)
+		}
+
+		slog.Warn(
+			"failed to discover categories, using the stored ones",
+			"retailer_id", retailerID,
+			"err", err,
+		)
+		return categories, nil
+	}
+
+	type node struct {
+		category entity.Category
+		children []supermarketapi.Category
+	}
+
+	categories := []entity.Category{}
+	seenPaths := map[string]bool{}
+	level := []node{}
+	for _, category := range tree {
+		if seenPaths[category.Slug] {
+			continue
+		}
+		seenPaths[category.Slug] = true
+
+		level = append(level, node{
+			category: entity.Category{
+				RetailerID: retailerID,
+				Name:       category.Name,
+				Slug:       category.Slug,
+				Path:       category.Slug,
+				Level:      1,
+			},
+			children: category.Children,
+		})
+	}
+
+	// Parents are saved before their children, which need their IDs.
+	for len(level) > 0 {
+		levelCategories := make([]entity.Category, len(level))
+		for i, n := range level {
+			levelCategories[i] = n.category
+		}
+
+		if err := u.db.UpsertCategories(ctx, levelCategories); err != nil {
+			return nil, errs.New(err)
+		}
+		categories = append(categories, levelCategories...)
+
+		nextLevel := []node{}
+		for i, n := range level {
+			parent := levelCategories[i]
+			for _, child := range n.children {
+				path := parent.Path + "/" + child.Slug
+				if seenPaths[path] {
+					continue
+				}
+				seenPaths[path] = true
+
+				nextLevel = append(nextLevel, node{
+					category: entity.Category{
+						RetailerID: retailerID,
+						ParentID:   &parent.ID,
+						Name:       child.Name,
+						Slug:       child.Slug,
+						Path:       path,
+						Level:      parent.Level + 1,
+					},
+					children: child.Children,
+				})
+			}
+		}
+		level = nextLevel
+	}
+
+	ids := make([]string, len(categories))
+	for i, category := range categories {
+		ids[i] = category.ID
+	}
+	if err := u.db.DeleteMissingCategories(ctx, retailerID, ids); err != nil {
+		return nil, errs.New(err)
+	}
+
+	slices.SortFunc(categories, func(a, b entity.Category) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return categories, nil
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
+)
+
+type SyncStoresUseCase struct {
+	db db.DB
+}
+
+func NewSyncStoresUseCase(db db.DB) *SyncStoresUseCase {
+	return &SyncStoresUseCase{
+		db: db,
+	}
+}
+
+// Execute resolves the stores configured for the retailer and stores them,
+// returning each store once even if several postal codes resolve to it.
+func (u *SyncStoresUseCase) Execute(
+	ctx context.Context,
+	api supermarketapi.SupermarketAPI,
+) ([]entity.Store, error) {
+	resolvedStores, err := api.ListStores(ctx)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	stores := []entity.Store{}
+	seenCodes := map[string]bool{}
+	for _, store := range resolvedStores {
+		if seenCodes[store.Code] {
+			continue
+		}
+		seenCodes[store.Code] = true
+		stores = append(stores, store)
+	}
+
+	if err := u.db.UpsertStores(ctx, stores); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return stores, nil
+}
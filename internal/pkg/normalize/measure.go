@@ -0,0 +1,253 @@
+package normalize
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Unit is the unit of measure of a product.
+type Unit string
+
+const (
+	UnitGram       Unit = "g"
+	UnitKilogram   Unit = "kg"
+	UnitMilliliter Unit = "ml"
+	UnitLiter      Unit = "L"
+	UnitUnit       Unit = "un"
+)
+
+// Attributes are the attributes of a product parsed from its name.
+type Attributes struct {
+	// Brand is a best guess, empty when the name has none.
+	Brand string
+	// Quantity is the size of each item of the pack, in Unit.
+	Quantity float64
+	Unit     Unit
+	// PackCount is how many items the product packs, 1 when it is not a pack.
+	PackCount int
+}
+
+var units = map[string]Unit{
+	"g":        UnitGram,
+	"gr":       UnitGram,
+	"grs":      UnitGram,
+	"grama":    UnitGram,
+	"gramas":   UnitGram,
+	"kg":       UnitKilogram,
+	"kgs":      UnitKilogram,
+	"quilo":    UnitKilogram,
+	"quilos":   UnitKilogram,
+	"ml":       UnitMilliliter,
+	"l":        UnitLiter,
+	"lt":       UnitLiter,
+	"lts":      UnitLiter,
+	"litro":    UnitLiter,
+	"litros":   UnitLiter,
+	"un":       UnitUnit,
+	"und":      UnitUnit,
+	"unds":     UnitUnit,
+	"unid":     UnitUnit,
+	"unidade":  UnitUnit,
+	"unidades": UnitUnit,
+}
+
+const (
+	numberPattern = `(\d+(?:[.,]\d+)?)`
+	unitPattern   = `(kgs?|quilos?|grs?|gramas?|g|ml|lts?|litros?|l|unidades?|unds?|unid|un)`
+)
+
+var (
+	// e.g. "6x350ml" or "6 x 350 ml".
+	packedMeasureRegexp = regexp.MustCompile(
+		`\b(\d+)\s*x\s*` + numberPattern + `\s*` + unitPattern + `\b`,
+	)
+	// e.g. "350ml x 6", "350ml c/ 6" or "350ml com 6".
+	measurePackRegexp = regexp.MustCompile(
+		`\b` + numberPattern + `\s*` + unitPattern + `\s*(?:x|c/|com)\s*(\d+)\b`,
+	)
+	// e.g. "5kg" or "1,5 l".
+	measureRegexp = regexp.MustCompile(
+		`\b` + numberPattern + `\s*` + unitPattern + `\b`,
+	)
+	// e.g. "c/ 6", "com 12" or "pack 6".
+	packRegexp = regexp.MustCompile(`(?:\bc/|\bcom\b|\bpack\b|\bfardo\b)\s*(\d+)\b`)
+)
+
+// Parse parses the brand, quantity, unit and pack count of a product from
+// its name, e.g. "Refrigerante Coca-Cola 2L 6 unidades" packs 6 items of
+// 2 L of the brand Coca-Cola. The zero Attributes is returned when the name
+// has no measure.
+func Parse(name string) Attributes {
+	attributes := Attributes{Brand: parseBrand(name)}
+
+	folded := Fold(name)
+
+	if m := packedMeasureRegexp.FindStringSubmatch(folded); m != nil {
+		attributes.PackCount, _ = strconv.Atoi(m[1])
+		attributes.Quantity = parseNumber(m[2])
+		attributes.Unit = units[m[3]]
+		return validate(attributes)
+	}
+
+	if m := measurePackRegexp.FindStringSubmatch(folded); m != nil {
+		attributes.Quantity = parseNumber(m[1])
+		attributes.Unit = units[m[2]]
+		attributes.PackCount, _ = strconv.Atoi(m[3])
+		return validate(attributes)
+	}
+
+	// A name may have both a measure and a count of units, e.g. "2L 6
+	// unidades", in which case the count of units is the pack count.
+	var count float64
+	for _, m := range measureRegexp.FindAllStringSubmatch(folded, -1) {
+		quantity, unit := parseNumber(m[1]), units[m[2]]
+		if unit == UnitUnit {
+			count = quantity
+			continue
+		}
+		if attributes.Unit == "" {
+			attributes.Quantity, attributes.Unit = quantity, unit
+		}
+	}
+
+	switch {
+	case attributes.Unit != "" && count > 0:
+		attributes.PackCount = int(count)
+	case attributes.Unit != "":
+		if m := packRegexp.FindStringSubmatch(folded); m != nil {
+			attributes.PackCount, _ = strconv.Atoi(m[1])
+		}
+	case count > 0:
+		attributes.Quantity, attributes.Unit = count, UnitUnit
+	}
+
+	return validate(attributes)
+}
+
+// UnitPrice returns the price per kg, L or unit of a product, and the unit
+// it is priced by. ok is false when the product has no measure.
+func UnitPrice(
+	price, quantity float64,
+	unit Unit,
+	packCount int,
+) (unitPrice float64, pricedBy Unit, ok bool) {
+	total, pricedBy, ok := Total(quantity, unit, packCount)
+	if price <= 0 || !ok {
+		return 0, "", false
+	}
+
+	return price / total, pricedBy, true
+}
+
+// Total returns the quantity of the whole pack in kg, L or units, e.g. 2.1 L
+// for 6 items of 350 ml. ok is false when the product has no measure.
+func Total(
+	quantity float64,
+	unit Unit,
+	packCount int,
+) (total float64, base Unit, ok bool) {
+	if quantity <= 0 {
+		return 0, "", false
+	}
+	packCount = max(packCount, 1)
+
+	switch unit {
+	case UnitGram:
+		quantity, base = quantity/1000, UnitKilogram
+	case UnitMilliliter:
+		quantity, base = quantity/1000, UnitLiter
+	case UnitKilogram, UnitLiter, UnitUnit:
+		base = unit
+	default:
+		return 0, "", false
+	}
+
+	return quantity * float64(packCount), base, true
+}
+
+func validate(attributes Attributes) Attributes {
+	if attributes.Unit == "" || attributes.Quantity <= 0 {
+		return Attributes{Brand: attributes.Brand}
+	}
+	attributes.PackCount = max(attributes.PackCount, 1)
+	return attributes
+}
+
+// parseNumber parses both "1,5" and "1.5" as 1.5, but "1.000" as 1000, as
+// Brazilian names use the dot as the thousands separator.
+func parseNumber(s string) float64 {
+	if i := strings.Index(s, "."); i >= 0 && len(s)-i-1 == 3 {
+		s = strings.Replace(s, ".", "", 1)
+	}
+	n, err := strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// descriptors are the words that describe the product rather than name its
+// brand, e.g. "Branco" in "Arroz Branco Tio João".
+var descriptors = map[string]bool{
+	"branco":       true,
+	"brancos":      true,
+	"carioca":      true,
+	"com":          true,
+	"de":           true,
+	"diet":         true,
+	"do":           true,
+	"da":           true,
+	"e":            true,
+	"em":           true,
+	"fardo":        true,
+	"garrafa":      true,
+	"integral":     true,
+	"lata":         true,
+	"light":        true,
+	"mineral":      true,
+	"original":     true,
+	"pacote":       true,
+	"pack":         true,
+	"parboilizado": true,
+	"pet":          true,
+	"po":           true,
+	"preto":        true,
+	"refinado":     true,
+	"refil":        true,
+	"sabor":        true,
+	"seco":         true,
+	"sem":          true,
+	"suave":        true,
+	"tipo":         true,
+	"tinto":        true,
+	"tradicional":  true,
+	"zero":         true,
+}
+
+// parseBrand guesses the brand as the first run of capitalized words after
+// the product type, e.g. "Tio João" in "Arroz Branco Tio João Tipo 1 5kg".
+func parseBrand(name string) string {
+	words := strings.Fields(name)
+	if len(words) < 2 {
+		return ""
+	}
+
+	brand := []string{}
+	for _, word := range words[1:] {
+		isDescriptor := descriptors[Fold(word)]
+		isMeasure := strings.ContainsFunc(word, unicode.IsDigit)
+		isCapitalized := unicode.IsUpper([]rune(word)[0])
+
+		if isDescriptor || isMeasure || !isCapitalized {
+			if len(brand) > 0 {
+				break
+			}
+			continue
+		}
+		brand = append(brand, word)
+	}
+
+	return strings.Join(brand, " ")
+}
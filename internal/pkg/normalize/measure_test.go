@@ -0,0 +1,282 @@
+package normalize
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		want Attributes
+	}{
+		{
+			name: "Refrigerante Coca-Cola Lata 6x350ml",
+			want: Attributes{
+				Brand:     "Coca-Cola",
+				Quantity:  350,
+				Unit:      UnitMilliliter,
+				PackCount: 6,
+			},
+		},
+		{
+			name: "Cerveja Skol Lata 350ml c/ 6",
+			want: Attributes{
+				Brand:     "Skol",
+				Quantity:  350,
+				Unit:      UnitMilliliter,
+				PackCount: 6,
+			},
+		},
+		{
+			name: "Água Mineral Crystal 1.500ml",
+			want: Attributes{
+				Brand:     "Crystal",
+				Quantity:  1500,
+				Unit:      UnitMilliliter,
+				PackCount: 1,
+			},
+		},
+		{
+			name: "Água Mineral Crystal 1,5 L",
+			want: Attributes{
+				Brand:     "Crystal",
+				Quantity:  1.5,
+				Unit:      UnitLiter,
+				PackCount: 1,
+			},
+		},
+		{
+			name: "Açúcar Refinado União 1kg",
+			want: Attributes{
+				Brand:     "União",
+				Quantity:  1,
+				Unit:      UnitKilogram,
+				PackCount: 1,
+			},
+		},
+		{
+			name: "Refrigerante Coca-Cola 2L 6 unidades",
+			want: Attributes{
+				Brand:     "Coca-Cola",
+				Quantity:  2,
+				Unit:      UnitLiter,
+				PackCount: 6,
+			},
+		},
+		{
+			name: "Sabonete Dove Pack 3 90g",
+			want: Attributes{
+				Brand:     "Dove",
+				Quantity:  90,
+				Unit:      UnitGram,
+				PackCount: 3,
+			},
+		},
+		{
+			name: "Ovos Brancos Mantiqueira 12 unidades",
+			want: Attributes{
+				Brand:     "Mantiqueira",
+				Quantity:  12,
+				Unit:      UnitUnit,
+				PackCount: 1,
+			},
+		},
+		{
+			name: "Arroz Branco Tio João Tipo 1 5kg",
+			want: Attributes{
+				Brand:     "Tio João",
+				Quantity:  5,
+				Unit:      UnitKilogram,
+				PackCount: 1,
+			},
+		},
+		{
+			name: "Vinho Tinto Casillero Del Diablo",
+			want: Attributes{Brand: "Casillero Del Diablo"},
+		},
+		{
+			name: "biscoito recheado de chocolate",
+			want: Attributes{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Parse(tt.name); got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnitPrice(t *testing.T) {
+	tests := []struct {
+		name         string
+		price        float64
+		quantity     float64
+		unit         Unit
+		packCount    int
+		wantPrice    float64
+		wantPricedBy Unit
+		wantOK       bool
+	}{
+		{
+			name:         "pack in ml priced by L",
+			price:        4.2,
+			quantity:     350,
+			unit:         UnitMilliliter,
+			packCount:    6,
+			wantPrice:    2,
+			wantPricedBy: UnitLiter,
+			wantOK:       true,
+		},
+		{
+			name:         "g priced by kg",
+			price:        10,
+			quantity:     500,
+			unit:         UnitGram,
+			packCount:    1,
+			wantPrice:    20,
+			wantPricedBy: UnitKilogram,
+			wantOK:       true,
+		},
+		{
+			name:         "units",
+			price:        12,
+			quantity:     12,
+			unit:         UnitUnit,
+			packCount:    1,
+			wantPrice:    1,
+			wantPricedBy: UnitUnit,
+			wantOK:       true,
+		},
+		{
+			name:      "no price",
+			price:     0,
+			quantity:  1,
+			unit:      UnitKilogram,
+			packCount: 1,
+		},
+		{
+			name:      "no measure",
+			price:     5,
+			packCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, pricedBy, ok := UnitPrice(
+				tt.price,
+				tt.quantity,
+				tt.unit,
+				tt.packCount,
+			)
+			if ok != tt.wantOK || pricedBy != tt.wantPricedBy ||
+				math.Abs(price-tt.wantPrice) > 1e-9 {
+				t.Errorf(
+					"UnitPrice() = %v, %q, %v, want %v, %q, %v",
+					price, pricedBy, ok,
+					tt.wantPrice, tt.wantPricedBy, tt.wantOK,
+				)
+			}
+		})
+	}
+}
+
+func TestTotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		quantity  float64
+		unit      Unit
+		packCount int
+		wantTotal float64
+		wantBase  Unit
+		wantOK    bool
+	}{
+		{
+			name:      "pack in ml",
+			quantity:  350,
+			unit:      UnitMilliliter,
+			packCount: 6,
+			wantTotal: 2.1,
+			wantBase:  UnitLiter,
+			wantOK:    true,
+		},
+		{
+			name:      "g",
+			quantity:  500,
+			unit:      UnitGram,
+			packCount: 1,
+			wantTotal: 0.5,
+			wantBase:  UnitKilogram,
+			wantOK:    true,
+		},
+		{
+			name:      "no pack count",
+			quantity:  2,
+			unit:      UnitLiter,
+			wantTotal: 2,
+			wantBase:  UnitLiter,
+			wantOK:    true,
+		},
+		{
+			name:      "units",
+			quantity:  12,
+			unit:      UnitUnit,
+			packCount: 1,
+			wantTotal: 12,
+			wantBase:  UnitUnit,
+			wantOK:    true,
+		},
+		{
+			name:      "no quantity",
+			unit:      UnitKilogram,
+			packCount: 1,
+		},
+		{
+			name:      "no unit",
+			quantity:  1,
+			packCount: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			total, base, ok := Total(tt.quantity, tt.unit, tt.packCount)
+			if ok != tt.wantOK || base != tt.wantBase ||
+				math.Abs(total-tt.wantTotal) > 1e-9 {
+				t.Errorf(
+					"Total() = %v, %q, %v, want %v, %q, %v",
+					total, base, ok,
+					tt.wantTotal, tt.wantBase, tt.wantOK,
+				)
+			}
+		})
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := []struct {
+		s    string
+		want float64
+	}{
+		{s: "350", want: 350},
+		{s: "1,5", want: 1.5},
+		{s: "1.5", want: 1.5},
+		{s: "0,75", want: 0.75},
+		{s: "12.50", want: 12.5},
+		{s: "1.000", want: 1000},
+		{s: "1.500", want: 1500},
+		{s: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.s, func(t *testing.T) {
+			if got := parseNumber(tt.s); got != tt.want {
+				t.Errorf("parseNumber(%q) = %v, want %v", tt.s, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Fold lowercases s and strips its diacritics, so "Feijão" becomes "feijao".
+func Fold(s string) string {
+	t := transform.Chain(
+		norm.NFD,
+		runes.Remove(runes.In(unicode.Mn)),
+		norm.NFC,
+	)
+
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+
+	return strings.ToLower(folded)
+}
+
+// Name normalizes a product name to be used as a fallback identity
+// when the retailer does not provide a product code.
+func Name(name string) string {
+	return strings.Join(strings.Fields(Fold(name)), " ")
+}
+
+// Words splits s into its lowercase words without diacritics, dropping
+// punctuation, so "Feijão, 1kg!" has the words "feijao" and "1kg".
+func Words(s string) []string {
+	return strings.FieldsFunc(Fold(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
@@ -0,0 +1,55 @@
+package normalize
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Similarity scores how alike two product names are, from 0 to 1, ignoring
+// case, accents, punctuation and measures, so "Refrigerante Coca-Cola 2L"
+// and "REFRIG COCA COLA 2 LITROS" still score high.
+func Similarity(a, b string) float64 {
+	return Trigrams(a).Similarity(Trigrams(b))
+}
+
+// TrigramSet is the set of character trigrams of a name, to be compared
+// against many others without being rebuilt.
+type TrigramSet map[string]struct{}
+
+// Trigrams returns the character trigrams of the words of the name.
+func Trigrams(name string) TrigramSet {
+	kept := []string{}
+	for _, word := range Words(name) {
+		if _, ok := units[word]; ok {
+			continue
+		}
+		if strings.ContainsFunc(word, unicode.IsDigit) {
+			continue
+		}
+		kept = append(kept, word)
+	}
+
+	runes := []rune(" " + strings.Join(kept, " ") + " ")
+	trigrams := TrigramSet{}
+	for i := 0; i+3 <= len(runes); i++ {
+		trigrams[string(runes[i:i+3])] = struct{}{}
+	}
+
+	return trigrams
+}
+
+// Similarity returns the Dice coefficient of both sets.
+func (t TrigramSet) Similarity(other TrigramSet) float64 {
+	if len(t) == 0 || len(other) == 0 {
+		return 0
+	}
+
+	shared := 0
+	for trigram := range t {
+		if _, ok := other[trigram]; ok {
+			shared++
+		}
+	}
+
+	return 2 * float64(shared) / float64(len(t)+len(other))
+}
@@ -0,0 +1,87 @@
+package normalize
+
+import "testing"
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		// The similarity must be within [min, max].
+		min, max float64
+	}{
+		{
+			name: "same name",
+			a:    "Arroz Tio João",
+			b:    "Arroz Tio João",
+			min:  1,
+			max:  1,
+		},
+		{
+			name: "case and accents",
+			a:    "Feijão Carioca Camil",
+			b:    "FEIJAO CARIOCA CAMIL",
+			min:  1,
+			max:  1,
+		},
+		{
+			name: "measures",
+			a:    "Arroz Tio João 5kg",
+			b:    "Arroz Tio João 1kg",
+			min:  1,
+			max:  1,
+		},
+		{
+			name: "abbreviations and punctuation",
+			a:    "Refrigerante Coca-Cola 2L",
+			b:    "REFRIG COCA COLA 2 LITROS",
+			min:  0.6,
+			max:  0.9,
+		},
+		{
+			name: "variants",
+			a:    "Cerveja Heineken Lata",
+			b:    "Cerveja Heineken Long Neck",
+			min:  0.6,
+			max:  0.9,
+		},
+		{
+			name: "different products",
+			a:    "Arroz Tio João",
+			b:    "Detergente Ypê Neutro",
+			min:  0,
+			max:  0,
+		},
+		{
+			name: "measures only",
+			a:    "5kg",
+			b:    "1kg",
+			min:  0,
+			max:  0,
+		},
+		{
+			name: "empty name",
+			a:    "",
+			b:    "Arroz",
+			min:  0,
+			max:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Similarity(tt.a, tt.b)
+			if got < tt.min || got > tt.max {
+				t.Errorf(
+					"Similarity(%q, %q) = %v, want within [%v, %v]",
+					tt.a, tt.b, got, tt.min, tt.max,
+				)
+			}
+			if reverse := Similarity(tt.b, tt.a); reverse != got {
+				t.Errorf(
+					"Similarity(%q, %q) = %v, not symmetric with %v",
+					tt.b, tt.a, reverse, got,
+				)
+			}
+		})
+	}
+}
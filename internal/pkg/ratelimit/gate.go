@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// ErrBudgetExhausted is returned once every request of the budget was spent.
+var ErrBudgetExhausted = errors.New("request budget exhausted")
+
+// Gate holds the limits every request of a scraper goes through, whatever
+// its concurrency: a minimum delay between the requests to the same host
+// and a total budget of requests. It is safe for concurrent use.
+type Gate struct {
+	hostDelay time.Duration
+	budget    int64
+	spent     atomic.Int64
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+// NewGate returns a gate that spaces out the requests to each host by
+// hostDelay and lets budget requests through in total. A zero hostDelay or
+// budget disables the respective limit.
+func NewGate(hostDelay time.Duration, budget int) *Gate {
+	return &Gate{
+		hostDelay: hostDelay,
+		budget:    int64(budget),
+		limiters:  map[string]*rate.Limiter{},
+	}
+}
+
+// Wait spends a request of the budget, then waits for the turn of the host.
+func (g *Gate) Wait(ctx context.Context, host string) error {
+	if g.budget > 0 && g.spent.Add(1) > g.budget {
+		return ErrBudgetExhausted
+	}
+
+	if g.hostDelay <= 0 {
+		return nil
+	}
+
+	return g.limiter(host).Wait(ctx)
+}
+
+// Exhausted reports whether every request of the budget was spent.
+func (g *Gate) Exhausted() bool {
+	return g.budget > 0 && g.spent.Load() >= g.budget
+}
+
+func (g *Gate) limiter(host string) *rate.Limiter {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	limiter, ok := g.limiters[host]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Every(g.hostDelay), 1)
+		g.limiters[host] = limiter
+	}
+	return limiter
+}
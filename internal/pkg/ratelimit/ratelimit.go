@@ -0,0 +1,42 @@
+package ratelimit
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// Transport is an http.RoundTripper that waits for the gate and the limiter
+// before sending each request, retries included.
+type Transport struct {
+	Base    http.RoundTripper
+	Gate    *Gate
+	Limiter *rate.Limiter
+}
+
+// NewTransport wraps base so that every request through it goes through the
+// gate and shares a limit of requestsPerSecond.
+func NewTransport(
+	base http.RoundTripper,
+	gate *Gate,
+	requestsPerSecond float64,
+) *Transport {
+	return &Transport{
+		Base:    base,
+		Gate:    gate,
+		Limiter: rate.NewLimiter(rate.Limit(requestsPerSecond), 1),
+	}
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.Gate.Wait(req.Context(), req.URL.Host); err != nil {
+		return nil, err
+	}
+	if err := t.Limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+
+	return t.Base.RoundTrip(req)
+}
+
+var _ http.RoundTripper = (*Transport)(nil)
@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+)
+
+// DB is the repository every storage backend implements.
+type DB interface {
+	Close() error
+
+	UpsertRetailer(ctx context.Context, retailer entity.Retailer) error
+
+	UpsertStores(ctx context.Context, stores []entity.Store) error
+	ListStores(ctx context.Context, retailerID string) ([]entity.Store, error)
+
+	UpsertCategories(ctx context.Context, categories []entity.Category) error
+	DeleteMissingCategories(
+		ctx context.Context,
+		retailerID string,
+		keepIDs []string,
+	) error
+	ListCategories(
+		ctx context.Context,
+		retailerID string,
+	) ([]entity.Category, error)
+
+	// CreateProducts sets the IDs of the products it creates. A product whose
+	// retailer and code are already taken has its catalog attributes updated
+	// instead, and takes the ID of the existing one.
+	CreateProducts(ctx context.Context, products []entity.Product) error
+	UpdateProducts(ctx context.Context, products []entity.Product) error
+	// ListProducts lists every product of the retailer, delisted ones
+	// included.
+	ListProducts(
+		ctx context.Context,
+		retailerID string,
+	) ([]entity.Product, error)
+	// ListProductsByCodes and ListProductsByNormalizedNames include the
+	// delisted products, so they can be relisted when they come back.
+	ListProductsByCodes(
+		ctx context.Context,
+		retailerID string,
+		codes []string,
+	) ([]entity.Product, error)
+	ListProductsByNormalizedNames(
+		ctx context.Context,
+		retailerID string,
+		names []string,
+	) ([]entity.Product, error)
+
+	// ListUnobservedProducts lists the listed products of the retailer of
+	// the run that neither the run nor the latest succeeded run of any other
+	// store observed, among those previously observed by runs of the same
+	// source.
+	ListUnobservedProducts(
+		ctx context.Context,
+		run entity.ScrapeRun,
+	) ([]entity.Product, error)
+	// ChangeProductListings delists or relists the products, recording the
+	// change along with the run that noticed it.
+	ChangeProductListings(
+		ctx context.Context,
+		productIDs []string,
+		runID *string,
+		status entity.ListingStatus,
+	) error
+	// ListUnmatchedProducts lists the products not matched to a canonical
+	// product yet.
+	ListUnmatchedProducts(ctx context.Context) ([]entity.Product, error)
+	ListProductsByIDs(
+		ctx context.Context,
+		ids []string,
+	) ([]entity.Product, error)
+
+	CreateCanonicalProducts(
+		ctx context.Context,
+		canonicalProducts []entity.CanonicalProduct,
+	) error
+	ListCanonicalProducts(
+		ctx context.Context,
+	) ([]entity.CanonicalProduct, error)
+
+	CreateProductMatches(ctx context.Context, matches []entity.ProductMatch) error
+	UpdateProductMatches(ctx context.Context, matches []entity.ProductMatch) error
+	ListProductMatches(ctx context.Context) ([]entity.ProductMatch, error)
+
+	// CreatePriceObservations records the observations, which become the
+	// current prices of their products at their stores.
+	CreatePriceObservations(
+		ctx context.Context,
+		observations []entity.PriceObservation,
+	) error
+	// ListProductPrices lists the current prices of the products at every
+	// store, or at the given one only.
+	ListProductPrices(
+		ctx context.Context,
+		productIDs []string,
+		storeID string,
+	) ([]entity.ProductPrice, error)
+	// ListPreviousPriceObservations lists the latest observation of each
+	// product made in the store by any run but the given one.
+	ListPreviousPriceObservations(
+		ctx context.Context,
+		productIDs []string,
+		storeID *string,
+		runID string,
+	) ([]entity.PriceObservation, error)
+
+	ListAlertRules(ctx context.Context) ([]entity.AlertRule, error)
+
+	CreateScrapeRun(ctx context.Context, run *entity.ScrapeRun) error
+	FinishScrapeRun(ctx context.Context, run entity.ScrapeRun) error
+	GetScrapeRun(ctx context.Context, id string) (*entity.ScrapeRun, error)
+	ReopenScrapeRun(ctx context.Context, run entity.ScrapeRun) error
+	// ListScrapeRuns lists the runs of the retailer, the oldest first.
+	ListScrapeRuns(
+		ctx context.Context,
+		retailerID string,
+	) ([]entity.ScrapeRun, error)
+
+	CreateScrapeCheckpoint(
+		ctx context.Context,
+		checkpoint entity.ScrapeCheckpoint,
+	) error
+	ListScrapeCheckpoints(
+		ctx context.Context,
+		runID string,
+	) ([]entity.ScrapeCheckpoint, error)
+
+	CreateRawPayload(ctx context.Context, payload entity.RawPayload) error
+	ListRawPayloads(
+		ctx context.Context,
+		runID string,
+		pagination Pagination,
+	) ([]entity.RawPayload, error)
+
+	CreateError(ctx context.Context, errRec entity.Error) error
+	ListErrorsByType(
+		ctx context.Context,
+		errType errs.ErrType,
+	) ([]entity.Error, error)
+	DeleteErrors(ctx context.Context, ids []string) error
+	// UpdateErrorAttempts stores the retry attempts of the errors, along
+	// with their last failure and whether they were given up on.
+	UpdateErrorAttempts(ctx context.Context, dbErrors []entity.Error) error
+
+	// The Find methods list a page of the records matching the filter, along
+	// with how many records match it in total.
+	FindProducts(
+		ctx context.Context,
+		filter ProductFilter,
+	) ([]entity.Product, int, error)
+	// SearchProducts finds the products whose name, brand or category match
+	// every word of the query, regardless of case and accents, the best
+	// matches first.
+	SearchProducts(
+		ctx context.Context,
+		query string,
+		filter ProductFilter,
+	) ([]entity.Product, int, error)
+	FindPriceObservations(
+		ctx context.Context,
+		filter PriceObservationFilter,
+	) ([]entity.PriceObservation, int, error)
+	FindCategories(
+		ctx context.Context,
+		filter CategoryFilter,
+	) ([]entity.Category, int, error)
+	FindScrapeRuns(
+		ctx context.Context,
+		filter ScrapeRunFilter,
+	) ([]entity.ScrapeRun, int, error)
+	FindErrors(
+		ctx context.Context,
+		filter ErrorFilter,
+	) ([]entity.Error, int, error)
+}
@@ -0,0 +1,21 @@
+package factory
+
+import (
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/postgres"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+)
+
+// NewDB opens the storage backend selected by DB_DRIVER.
+func NewDB(
+	e *env.Env,
+) db.DB {
+	switch e.DBDriver {
+	case env.DBDriverPostgres:
+		return postgres.New(e)
+
+	default:
+		return sqlite.New(e)
+	}
+}
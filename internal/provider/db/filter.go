@@ -0,0 +1,65 @@
+package db
+
+import "time"
+
+// Pagination selects a page of a listing, pages counting from 1.
+type Pagination struct {
+	Page     int
+	PageSize int
+}
+
+func (p Pagination) Limit() uint {
+	return uint(max(p.PageSize, 1))
+}
+
+func (p Pagination) Offset() uint {
+	return uint(max(p.Page-1, 0)) * p.Limit()
+}
+
+type ProductFilter struct {
+	Pagination
+	RetailerID string
+	// CategoryID also matches the products of its subcategories.
+	CategoryID string
+	Brand      string
+	// StoreID matches the products with a current price at the store, which
+	// MinPrice and MaxPrice then apply to. Otherwise they match a current
+	// price at any store.
+	StoreID  string
+	MinPrice *float64
+	MaxPrice *float64
+	// IncludeDelisted also matches the products no longer listed.
+	IncludeDelisted bool
+}
+
+type PriceObservationFilter struct {
+	Pagination
+	ProductID string
+	StoreID   string
+	From      *time.Time
+	To        *time.Time
+}
+
+type CategoryFilter struct {
+	Pagination
+	RetailerID string
+	ParentID   string
+	// RootOnly matches the top-level categories only.
+	RootOnly bool
+}
+
+type ScrapeRunFilter struct {
+	Pagination
+	RetailerID string
+	StoreID    string
+	Source     string
+	Status     string
+}
+
+type ErrorFilter struct {
+	Pagination
+	RunID string
+	Type  string
+	// IncludeResolved also matches the errors a retry resolved.
+	IncludeResolved bool
+}
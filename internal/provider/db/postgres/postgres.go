@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"log"
+
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/postgres"
+	_ "github.com/jackc/pgx/v5/stdlib"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/schema"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqldb"
+)
+
+// Dialect opens Postgres databases through the pgx driver.
+var Dialect = sqldb.Dialect{
+	Driver: "pgx",
+	Name:   "postgres",
+	Search: search,
+}
+
+func New(
+	e *env.Env,
+) *sqldb.DB {
+	db, err := sqldb.Open(Dialect, e.PostgresDBURL)
+	if err != nil {
+		log.Fatalf("failed to open database: %v", err)
+	}
+
+	return db
+}
+
+// search finds the products whose normalized name has every word, as
+// Postgres has no full-text index of the products yet.
+func search(gdb *goqu.Database, words []string) *goqu.SelectDataset {
+	ds := gdb.From(schema.Product.String())
+	for _, word := range words {
+		ds = ds.Where(
+			goqu.I(schema.Product.NormalizedName()).Like("%" + word + "%"),
+		)
+	}
+
+	return ds
+}
@@ -0,0 +1,1916 @@
+package sqldb
+
+import (
+	"context"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/normalize"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/schema"
+)
+
+// Dialect is what sets a storage backend apart: the driver it is opened
+// with, the SQL dialect its queries are built in, the checks it must pass
+// on startup and how it searches products.
+type Dialect struct {
+	// Driver is the name of the database/sql driver.
+	Driver string
+
+	// Name is the name of the goqu dialect.
+	Name string
+
+	// Check fails when the database lacks something the queries need.
+	Check func(db *sqlx.DB) error
+
+	// Search selects the products matching every word of a search query,
+	// best matches first.
+	Search func(gdb *goqu.Database, words []string) *goqu.SelectDataset
+
+	// Timestamp converts a time to the value stored timestamps are compared
+	// with, the time in UTC when nil.
+	Timestamp func(t time.Time) any
+}
+
+type DB struct {
+	db      *sqlx.DB
+	gdb     *goqu.Database
+	dialect Dialect
+}
+
+// Open opens the database at dataSourceName in the dialect.
+func Open(dialect Dialect, dataSourceName string) (*DB, error) {
+	db, err := sqlx.Open(dialect.Driver, dataSourceName)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	if dialect.Check != nil {
+		if err := dialect.Check(db); err != nil {
+			_ = db.Close()
+			return nil, errs.New(err)
+		}
+	}
+
+	gdb := goqu.New(dialect.Name, db.DB)
+
+	return &DB{
+		db:      db,
+		gdb:     gdb,
+		dialect: dialect,
+	}, nil
+}
+
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// timestamp converts t to the value stored timestamps are compared with.
+func (d *DB) timestamp(t time.Time) any {
+	if d.dialect.Timestamp == nil {
+		return t.UTC()
+	}
+	return d.dialect.Timestamp(t)
+}
+
+func (d *DB) UpsertRetailer(
+	ctx context.Context,
+	retailer entity.Retailer,
+) error {
+	ds := d.gdb.
+		Insert(schema.Retailer.String()).
+		Rows(goqu.Record{
+			"id":   retailer.ID,
+			"name": retailer.Name,
+		}).
+		OnConflict(goqu.DoUpdate("id", goqu.Record{
+			"name": goqu.L("excluded.name"),
+		}))
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// UpsertCategories creates the categories or updates the ones that already
+// exist with the same retailer and path, restoring them if they had been
+// deleted. The ID of every category is set afterwards.
+func (d *DB) UpsertCategories(
+	ctx context.Context,
+	categories []entity.Category,
+) error {
+	if len(categories) == 0 {
+		return nil
+	}
+
+	records := make([]goqu.Record, len(categories))
+	for i, category := range categories {
+		records[i] = goqu.Record{
+			"id":          uuid.New().String(),
+			"retailer_id": category.RetailerID,
+			"parent_id":   category.ParentID,
+			"name":        category.Name,
+			"slug":        category.Slug,
+			"path":        category.Path,
+			"level":       category.Level,
+		}
+	}
+
+	ds := d.gdb.
+		Insert(schema.Category.String()).
+		Rows(records).
+		OnConflict(goqu.DoUpdate("retailer_id, path", goqu.Record{
+			"parent_id":  goqu.L("excluded.parent_id"),
+			"name":       goqu.L("excluded.name"),
+			"slug":       goqu.L("excluded.slug"),
+			"level":      goqu.L("excluded.level"),
+			"updated_at": goqu.L("CURRENT_TIMESTAMP"),
+			"deleted_at": nil,
+		}))
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	idsByKey := map[string]string{}
+	pathsByRetailer := map[string][]string{}
+	for _, category := range categories {
+		pathsByRetailer[category.RetailerID] = append(
+			pathsByRetailer[category.RetailerID],
+			category.Path,
+		)
+	}
+	for retailerID, paths := range pathsByRetailer {
+		ds := d.gdb.
+			From(schema.Category.String()).
+			Select(schema.Category.ID(), schema.Category.Path()).
+			Where(goqu.Ex{
+				schema.Category.RetailerID(): retailerID,
+				schema.Category.Path():       paths,
+			})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		var rows []entity.Category
+		if err := d.db.SelectContext(ctx, &rows, sql, args...); err != nil {
+			return errs.New(err)
+		}
+
+		for _, row := range rows {
+			idsByKey[retailerID+":"+row.Path] = row.ID
+		}
+	}
+
+	for i := range categories {
+		key := categories[i].RetailerID + ":" + categories[i].Path
+		categories[i].ID = idsByKey[key]
+	}
+
+	return nil
+}
+
+// DeleteMissingCategories soft-deletes the categories of the retailer that
+// are not in keepIDs.
+func (d *DB) DeleteMissingCategories(
+	ctx context.Context,
+	retailerID string,
+	keepIDs []string,
+) error {
+	where := goqu.Ex{
+		schema.Category.RetailerID(): retailerID,
+		schema.Category.DeletedAt():  nil,
+	}
+	if len(keepIDs) > 0 {
+		where[schema.Category.ID()] = goqu.Op{"notIn": keepIDs}
+	}
+
+	ds := d.gdb.
+		Update(schema.Category.String()).
+		Set(goqu.Record{"deleted_at": goqu.L("CURRENT_TIMESTAMP")}).
+		Where(where)
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) ListCategories(
+	ctx context.Context,
+	retailerID string,
+) ([]entity.Category, error) {
+	ds := d.gdb.
+		From(schema.Category.String()).
+		Select(schema.Category.All()).
+		Where(goqu.Ex{
+			schema.Category.RetailerID(): retailerID,
+			schema.Category.DeletedAt():  nil,
+		}).
+		Order(goqu.I(schema.Category.Path()).Asc())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var categories []entity.Category
+	err = d.db.SelectContext(ctx, &categories, sql, args...)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	return categories, nil
+}
+
+// UpsertStores creates the stores or updates the ones that already exist
+// with the same retailer and code. The ID of every store is set afterwards.
+func (d *DB) UpsertStores(
+	ctx context.Context,
+	stores []entity.Store,
+) error {
+	if len(stores) == 0 {
+		return nil
+	}
+
+	records := make([]goqu.Record, len(stores))
+	for i, store := range stores {
+		records[i] = goqu.Record{
+			"id":          uuid.New().String(),
+			"retailer_id": store.RetailerID,
+			"code":        store.Code,
+			"name":        store.Name,
+			"region_id":   store.RegionID,
+			"postal_code": store.PostalCode,
+		}
+	}
+
+	ds := d.gdb.
+		Insert(schema.Store.String()).
+		Rows(records).
+		OnConflict(goqu.DoUpdate("retailer_id, code", goqu.Record{
+			"name":        goqu.L("excluded.name"),
+			"region_id":   goqu.L("excluded.region_id"),
+			"postal_code": goqu.L("excluded.postal_code"),
+			"updated_at":  goqu.L("CURRENT_TIMESTAMP"),
+		}))
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	for i := range stores {
+		ds := d.gdb.
+			From(schema.Store.String()).
+			Select(schema.Store.ID()).
+			Where(goqu.Ex{
+				schema.Store.RetailerID(): stores[i].RetailerID,
+				schema.Store.Code():       stores[i].Code,
+			})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		err = d.db.GetContext(ctx, &stores[i].ID, sql, args...)
+		if err != nil {
+			return errs.New(err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) ListStores(
+	ctx context.Context,
+	retailerID string,
+) ([]entity.Store, error) {
+	ds := d.gdb.
+		From(schema.Store.String()).
+		Select(schema.Store.All()).
+		Where(goqu.Ex{schema.Store.RetailerID(): retailerID}).
+		Order(goqu.I(schema.Store.Code()).Asc())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var stores []entity.Store
+	if err := d.db.SelectContext(ctx, &stores, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return stores, nil
+}
+
+func (d *DB) CreateProducts(
+	ctx context.Context,
+	products []entity.Product,
+) error {
+	const batchSize = 500
+
+	if len(products) == 0 {
+		return nil
+	}
+
+	for i := 0; i < len(products); i += batchSize {
+		end := min(i+batchSize, len(products))
+
+		batch := products[i:end]
+		var records []goqu.Record
+
+		for i := range batch {
+			batch[i].ID = uuid.New().String()
+
+			record := goqu.Record{
+				"id":                     batch[i].ID,
+				"retailer_id":            batch[i].RetailerID,
+				"name":                   batch[i].Name,
+				"normalized_name":        batch[i].NormalizedName,
+				"price":                  batch[i].Price,
+				"list_price":             batch[i].ListPrice,
+				"promo_price":            batch[i].PromoPrice,
+				"wholesale_price":        batch[i].WholesalePrice,
+				"wholesale_min_quantity": batch[i].WholesaleMinQuantity,
+				"brand":                  batch[i].Brand,
+				"parsed_brand":           batch[i].ParsedBrand,
+				"quantity":               batch[i].Quantity,
+				"unit":                   batch[i].Unit,
+				"pack_count":             batch[i].PackCount,
+				"code":                   batch[i].Code,
+				"gtin":                   batch[i].GTIN,
+				"slug":                   batch[i].Slug,
+				"url":                    batch[i].URL,
+				"image_url":              batch[i].ImageURL,
+				"breadcrumb":             batch[i].Breadcrumb,
+				"seller":                 batch[i].Seller,
+				"available":              batch[i].Available,
+				"category_id":            batch[i].CategoryID,
+			}
+
+			records = append(records, record)
+		}
+
+		// Runs of the same retailer save concurrently, so another one may
+		// have created a product with the same code since it was looked up.
+		ds := d.gdb.
+			Insert(schema.Product.String()).
+			Rows(records).
+			OnConflict(goqu.DoUpdate("retailer_id, code", goqu.Record{
+				"name":            goqu.L("excluded.name"),
+				"normalized_name": goqu.L("excluded.normalized_name"),
+				"brand":           goqu.L("excluded.brand"),
+				"parsed_brand":    goqu.L("excluded.parsed_brand"),
+				"quantity":        goqu.L("excluded.quantity"),
+				"unit":            goqu.L("excluded.unit"),
+				"pack_count":      goqu.L("excluded.pack_count"),
+			}))
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+
+		if err := d.setProductIDs(ctx, batch); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	return nil
+}
+
+// setProductIDs sets the IDs of the products with a code to the ones they
+// were stored with, which differ from the generated ones when they already
+// existed.
+func (d *DB) setProductIDs(
+	ctx context.Context,
+	products []entity.Product,
+) error {
+	codesByRetailer := map[string][]string{}
+	for _, product := range products {
+		if product.Code == nil {
+			continue
+		}
+		codesByRetailer[product.RetailerID] = append(
+			codesByRetailer[product.RetailerID],
+			*product.Code,
+		)
+	}
+
+	ids := map[[2]string]string{}
+	for retailerID, codes := range codesByRetailer {
+		stored, err := d.ListProductsByCodes(ctx, retailerID, codes)
+		if err != nil {
+			return errs.New(err)
+		}
+		for _, product := range stored {
+			ids[[2]string{retailerID, *product.Code}] = product.ID
+		}
+	}
+
+	for i := range products {
+		if products[i].Code == nil {
+			continue
+		}
+		id, ok := ids[[2]string{products[i].RetailerID, *products[i].Code}]
+		if ok {
+			products[i].ID = id
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) CreateError(
+	ctx context.Context,
+	errRec entity.Error,
+) error {
+	errRec.ID = uuid.New().String()
+
+	ds := d.gdb.Insert(schema.Error.String()).Rows(goqu.Record{
+		"id":          errRec.ID,
+		"run_id":      errRec.RunID,
+		"message":     errRec.Message,
+		"type":        errRec.Type,
+		"stack_trace": errRec.StackTrace,
+		"metadata":    errRec.Metadata,
+	})
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) ListErrorsByType(
+	ctx context.Context,
+	errType errs.ErrType,
+) ([]entity.Error, error) {
+	ds := d.gdb.
+		From(schema.Error.String()).
+		Select(schema.Error.All()).
+		Where(
+			goqu.Ex{
+				schema.Error.DeletedAt():           nil,
+				schema.Error.PermanentlyFailedAt(): nil,
+				schema.Error.Type():                string(errType),
+			},
+		).
+		Order(goqu.I(schema.Error.CreatedAt()).Desc())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var errors []entity.Error
+	if err := d.db.SelectContext(ctx, &errors, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return errors, nil
+}
+
+func (d *DB) DeleteErrors(
+	ctx context.Context,
+	ids []string,
+) error {
+	ds := d.gdb.
+		Update(schema.Error.String()).
+		Set(goqu.Record{"deleted_at": time.Now()}).
+		Where(goqu.Ex{schema.Error.ID(): ids})
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	_, err = d.db.ExecContext(ctx, sql, args...)
+	if err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) UpdateErrorAttempts(
+	ctx context.Context,
+	dbErrors []entity.Error,
+) error {
+	if len(dbErrors) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errs.New(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, errRec := range dbErrors {
+		ds := d.gdb.
+			Update(schema.Error.String()).
+			Set(goqu.Record{
+				"attempts":              errRec.Attempts,
+				"last_failure":          errRec.LastFailure,
+				"last_attempt_at":       errRec.LastAttemptAt,
+				"permanently_failed_at": errRec.PermanentlyFailedAt,
+			}).
+			Where(goqu.Ex{schema.Error.ID(): errRec.ID})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) ListProductsByCodes(
+	ctx context.Context,
+	retailerID string,
+	codes []string,
+) ([]entity.Product, error) {
+	return d.listProductsBy(ctx, retailerID, schema.Product.Code(), codes)
+}
+
+func (d *DB) ListProductsByNormalizedNames(
+	ctx context.Context,
+	retailerID string,
+	names []string,
+) ([]entity.Product, error) {
+	return d.listProductsBy(
+		ctx,
+		retailerID,
+		schema.Product.NormalizedName(),
+		names,
+	)
+}
+
+func (d *DB) listProductsBy(
+	ctx context.Context,
+	retailerID string,
+	column string,
+	values []string,
+) ([]entity.Product, error) {
+	const batchSize = 500
+
+	products := []entity.Product{}
+	for i := 0; i < len(values); i += batchSize {
+		end := min(i+batchSize, len(values))
+
+		ds := d.gdb.
+			From(schema.Product.String()).
+			Select(schema.Product.All()).
+			Where(
+				goqu.Ex{
+					schema.Product.RetailerID(): retailerID,
+					column:                      values[i:end],
+				},
+			)
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return nil, errs.New(err)
+		}
+
+		var batch []entity.Product
+		if err := d.db.SelectContext(ctx, &batch, sql, args...); err != nil {
+			return nil, errs.New(err)
+		}
+
+		products = append(products, batch...)
+	}
+
+	return products, nil
+}
+
+func (d *DB) UpdateProducts(
+	ctx context.Context,
+	products []entity.Product,
+) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errs.New(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, product := range products {
+		ds := d.gdb.
+			Update(schema.Product.String()).
+			Set(goqu.Record{
+				"name":                   product.Name,
+				"normalized_name":        product.NormalizedName,
+				"price":                  product.Price,
+				"list_price":             product.ListPrice,
+				"promo_price":            product.PromoPrice,
+				"wholesale_price":        product.WholesalePrice,
+				"wholesale_min_quantity": product.WholesaleMinQuantity,
+				"brand":                  product.Brand,
+				"parsed_brand":           product.ParsedBrand,
+				"quantity":               product.Quantity,
+				"unit":                   product.Unit,
+				"pack_count":             product.PackCount,
+				"code":                   product.Code,
+				"gtin":                   product.GTIN,
+				"slug":                   product.Slug,
+				"url":                    product.URL,
+				"image_url":              product.ImageURL,
+				"breadcrumb":             product.Breadcrumb,
+				"seller":                 product.Seller,
+				"available":              product.Available,
+				"category_id":            product.CategoryID,
+			}).
+			Where(goqu.Ex{schema.Product.ID(): product.ID})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) CreatePriceObservations(
+	ctx context.Context,
+	observations []entity.PriceObservation,
+) error {
+	const batchSize = 500
+
+	if len(observations) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errs.New(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i := 0; i < len(observations); i += batchSize {
+		end := min(i+batchSize, len(observations))
+
+		batch := observations[i:end]
+		var records []goqu.Record
+
+		// A product observed twice at a store gets its latest prices, as
+		// a row cannot be upserted twice in one statement.
+		priceRecords := map[string]goqu.Record{}
+		priceIDs := []string{}
+
+		for i := range batch {
+			batch[i].ID = uuid.New().String()
+
+			record := goqu.Record{
+				"id":                     batch[i].ID,
+				"product_id":             batch[i].ProductID,
+				"price":                  batch[i].Price,
+				"list_price":             batch[i].ListPrice,
+				"promo_price":            batch[i].PromoPrice,
+				"wholesale_price":        batch[i].WholesalePrice,
+				"wholesale_min_quantity": batch[i].WholesaleMinQuantity,
+				"unit_price":             batch[i].UnitPrice,
+				"unit_price_unit":        batch[i].UnitPriceUnit,
+				"available":              batch[i].Available,
+				"source":                 batch[i].Source,
+				"run_id":                 batch[i].RunID,
+				"store_id":               batch[i].StoreID,
+			}
+
+			records = append(records, record)
+
+			priceID := productPriceID(batch[i].ProductID, batch[i].StoreID)
+			if _, ok := priceRecords[priceID]; !ok {
+				priceIDs = append(priceIDs, priceID)
+			}
+			priceRecords[priceID] = goqu.Record{
+				"id":                     priceID,
+				"product_id":             batch[i].ProductID,
+				"store_id":               batch[i].StoreID,
+				"price":                  batch[i].Price,
+				"list_price":             batch[i].ListPrice,
+				"promo_price":            batch[i].PromoPrice,
+				"wholesale_price":        batch[i].WholesalePrice,
+				"wholesale_min_quantity": batch[i].WholesaleMinQuantity,
+				"unit_price":             batch[i].UnitPrice,
+				"unit_price_unit":        batch[i].UnitPriceUnit,
+				"available":              batch[i].Available,
+			}
+		}
+
+		ds := d.gdb.
+			Insert(schema.PriceObservation.String()).
+			Rows(records)
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+
+		prices := make([]goqu.Record, len(priceIDs))
+		for i, priceID := range priceIDs {
+			prices[i] = priceRecords[priceID]
+		}
+
+		pricesDs := d.gdb.
+			Insert(schema.ProductPrice.String()).
+			Rows(prices).
+			OnConflict(goqu.DoUpdate("id", goqu.Record{
+				"price":                  goqu.L("excluded.price"),
+				"list_price":             goqu.L("excluded.list_price"),
+				"promo_price":            goqu.L("excluded.promo_price"),
+				"wholesale_price":        goqu.L("excluded.wholesale_price"),
+				"wholesale_min_quantity": goqu.L("excluded.wholesale_min_quantity"),
+				"unit_price":             goqu.L("excluded.unit_price"),
+				"unit_price_unit":        goqu.L("excluded.unit_price_unit"),
+				"available":              goqu.L("excluded.available"),
+				"observed_at":            goqu.L("CURRENT_TIMESTAMP"),
+			}))
+		sql, args, err = pricesDs.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// productPriceID derives the ID of the current prices of the product at the
+// store, so that upserts find them even when there is no store.
+func productPriceID(productID string, storeID *string) string {
+	if storeID == nil {
+		return productID + ":"
+	}
+	return productID + ":" + *storeID
+}
+
+func (d *DB) ListProductPrices(
+	ctx context.Context,
+	productIDs []string,
+	storeID string,
+) ([]entity.ProductPrice, error) {
+	const batchSize = 500
+
+	prices := []entity.ProductPrice{}
+	for i := 0; i < len(productIDs); i += batchSize {
+		end := min(i+batchSize, len(productIDs))
+
+		ds := d.gdb.
+			From(schema.ProductPrice.String()).
+			Select(schema.ProductPrice.All()).
+			Where(goqu.Ex{schema.ProductPrice.ProductID(): productIDs[i:end]}).
+			Order(
+				goqu.I(schema.ProductPrice.ProductID()).Asc(),
+				goqu.I(schema.ProductPrice.Price()).Asc(),
+			)
+		if storeID != "" {
+			ds = ds.Where(goqu.Ex{schema.ProductPrice.StoreID(): storeID})
+		}
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return nil, errs.New(err)
+		}
+
+		var batch []entity.ProductPrice
+		if err := d.db.SelectContext(ctx, &batch, sql, args...); err != nil {
+			return nil, errs.New(err)
+		}
+		prices = append(prices, batch...)
+	}
+
+	return prices, nil
+}
+
+func (d *DB) CreateScrapeRun(
+	ctx context.Context,
+	run *entity.ScrapeRun,
+) error {
+	run.ID = uuid.New().String()
+
+	ds := d.gdb.Insert(schema.ScrapeRun.String()).Rows(goqu.Record{
+		"id":              run.ID,
+		"retailer_id":     run.RetailerID,
+		"store_id":        run.StoreID,
+		"source":          run.Source,
+		"kind":            run.Kind,
+		"status":          run.Status,
+		"page_size":       run.PageSize,
+		"category_counts": run.CategoryCounts,
+	})
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) FinishScrapeRun(
+	ctx context.Context,
+	run entity.ScrapeRun,
+) error {
+	ds := d.gdb.
+		Update(schema.ScrapeRun.String()).
+		Set(goqu.Record{
+			"status":          run.Status,
+			"product_count":   run.ProductCount,
+			"error_count":     run.ErrorCount,
+			"category_counts": run.CategoryCounts,
+			"finished_at":     goqu.L("CURRENT_TIMESTAMP"),
+		}).
+		Where(goqu.Ex{schema.ScrapeRun.ID(): run.ID})
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// GetScrapeRun returns the run with the given ID, or nil if there is none.
+func (d *DB) GetScrapeRun(
+	ctx context.Context,
+	id string,
+) (*entity.ScrapeRun, error) {
+	ds := d.gdb.
+		From(schema.ScrapeRun.String()).
+		Select(schema.ScrapeRun.All()).
+		Where(goqu.Ex{schema.ScrapeRun.ID(): id})
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var runs []entity.ScrapeRun
+	if err := d.db.SelectContext(ctx, &runs, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	if len(runs) == 0 {
+		return nil, nil
+	}
+
+	return &runs[0], nil
+}
+
+func (d *DB) ReopenScrapeRun(
+	ctx context.Context,
+	run entity.ScrapeRun,
+) error {
+	ds := d.gdb.
+		Update(schema.ScrapeRun.String()).
+		Set(goqu.Record{
+			"kind":        run.Kind,
+			"status":      run.Status,
+			"page_size":   run.PageSize,
+			"finished_at": nil,
+		}).
+		Where(goqu.Ex{schema.ScrapeRun.ID(): run.ID})
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) CreateScrapeCheckpoint(
+	ctx context.Context,
+	checkpoint entity.ScrapeCheckpoint,
+) error {
+	checkpoint.ID = uuid.New().String()
+
+	ds := d.gdb.
+		Insert(schema.ScrapeCheckpoint.String()).
+		Rows(goqu.Record{
+			"id":            checkpoint.ID,
+			"run_id":        checkpoint.RunID,
+			"category":      checkpoint.Category,
+			"page":          checkpoint.Page,
+			"total_pages":   checkpoint.TotalPages,
+			"product_count": checkpoint.ProductCount,
+		}).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) ListScrapeCheckpoints(
+	ctx context.Context,
+	runID string,
+) ([]entity.ScrapeCheckpoint, error) {
+	ds := d.gdb.
+		From(schema.ScrapeCheckpoint.String()).
+		Select(schema.ScrapeCheckpoint.All()).
+		Where(goqu.Ex{schema.ScrapeCheckpoint.RunID(): runID}).
+		Order(
+			goqu.I(schema.ScrapeCheckpoint.Category()).Asc(),
+			goqu.I(schema.ScrapeCheckpoint.Page()).Asc(),
+		)
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var checkpoints []entity.ScrapeCheckpoint
+	err = d.db.SelectContext(ctx, &checkpoints, sql, args...)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	return checkpoints, nil
+}
+
+var _ db.DB = (*DB)(nil)
+
+// ListUnmatchedProducts lists the products not matched to a canonical
+// product yet.
+func (d *DB) ListUnmatchedProducts(
+	ctx context.Context,
+) ([]entity.Product, error) {
+	ds := d.gdb.
+		From(schema.Product.String()).
+		Select(schema.Product.All()).
+		LeftJoin(
+			goqu.T(schema.ProductMatch.String()),
+			goqu.On(goqu.Ex{
+				schema.ProductMatch.ProductID(): goqu.I(schema.Product.ID()),
+			}),
+		).
+		Where(goqu.Ex{
+			schema.Product.DeletedAt(): nil,
+			schema.ProductMatch.ID():   nil,
+		}).
+		Order(goqu.I(schema.Product.CreatedAt()).Asc())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var products []entity.Product
+	if err := d.db.SelectContext(ctx, &products, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return products, nil
+}
+
+func (d *DB) ListProducts(
+	ctx context.Context,
+	retailerID string,
+) ([]entity.Product, error) {
+	ds := d.gdb.
+		From(schema.Product.String()).
+		Select(schema.Product.All()).
+		Where(goqu.Ex{schema.Product.RetailerID(): retailerID})
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var products []entity.Product
+	if err := d.db.SelectContext(ctx, &products, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return products, nil
+}
+
+func (d *DB) ListProductsByIDs(
+	ctx context.Context,
+	ids []string,
+) ([]entity.Product, error) {
+	const batchSize = 500
+
+	products := []entity.Product{}
+	for i := 0; i < len(ids); i += batchSize {
+		end := min(i+batchSize, len(ids))
+
+		ds := d.gdb.
+			From(schema.Product.String()).
+			Select(schema.Product.All()).
+			Where(goqu.Ex{schema.Product.ID(): ids[i:end]})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return nil, errs.New(err)
+		}
+
+		var batch []entity.Product
+		if err := d.db.SelectContext(ctx, &batch, sql, args...); err != nil {
+			return nil, errs.New(err)
+		}
+
+		products = append(products, batch...)
+	}
+
+	return products, nil
+}
+
+func (d *DB) CreateCanonicalProducts(
+	ctx context.Context,
+	canonicalProducts []entity.CanonicalProduct,
+) error {
+	const batchSize = 500
+
+	if len(canonicalProducts) == 0 {
+		return nil
+	}
+
+	for i := 0; i < len(canonicalProducts); i += batchSize {
+		end := min(i+batchSize, len(canonicalProducts))
+
+		batch := canonicalProducts[i:end]
+		var records []goqu.Record
+
+		for i := range batch {
+			batch[i].ID = uuid.New().String()
+
+			record := goqu.Record{
+				"id":              batch[i].ID,
+				"name":            batch[i].Name,
+				"normalized_name": batch[i].NormalizedName,
+				"brand":           batch[i].Brand,
+				"quantity":        batch[i].Quantity,
+				"unit":            batch[i].Unit,
+				"pack_count":      batch[i].PackCount,
+				"gtin":            batch[i].GTIN,
+			}
+
+			records = append(records, record)
+		}
+
+		ds := d.gdb.Insert(schema.CanonicalProduct.String()).Rows(records)
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) ListCanonicalProducts(
+	ctx context.Context,
+) ([]entity.CanonicalProduct, error) {
+	ds := d.gdb.
+		From(schema.CanonicalProduct.String()).
+		Select(schema.CanonicalProduct.All())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var canonicalProducts []entity.CanonicalProduct
+	err = d.db.SelectContext(ctx, &canonicalProducts, sql, args...)
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	return canonicalProducts, nil
+}
+
+func (d *DB) CreateProductMatches(
+	ctx context.Context,
+	matches []entity.ProductMatch,
+) error {
+	const batchSize = 500
+
+	if len(matches) == 0 {
+		return nil
+	}
+
+	for i := 0; i < len(matches); i += batchSize {
+		end := min(i+batchSize, len(matches))
+
+		batch := matches[i:end]
+		var records []goqu.Record
+
+		for i := range batch {
+			batch[i].ID = uuid.New().String()
+
+			record := goqu.Record{
+				"id":                   batch[i].ID,
+				"product_id":           batch[i].ProductID,
+				"canonical_product_id": batch[i].CanonicalProductID,
+				"retailer_id":          batch[i].RetailerID,
+				"method":               batch[i].Method,
+				"status":               batch[i].Status,
+				"confidence":           batch[i].Confidence,
+			}
+
+			records = append(records, record)
+		}
+
+		ds := d.gdb.Insert(schema.ProductMatch.String()).Rows(records)
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	return nil
+}
+
+func (d *DB) UpdateProductMatches(
+	ctx context.Context,
+	matches []entity.ProductMatch,
+) error {
+	if len(matches) == 0 {
+		return nil
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errs.New(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for _, match := range matches {
+		ds := d.gdb.
+			Update(schema.ProductMatch.String()).
+			Set(goqu.Record{
+				"canonical_product_id": match.CanonicalProductID,
+				"method":               match.Method,
+				"status":               match.Status,
+				"confidence":           match.Confidence,
+				"updated_at":           goqu.L("CURRENT_TIMESTAMP"),
+			}).
+			Where(goqu.Ex{schema.ProductMatch.ID(): match.ID})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) ListProductMatches(
+	ctx context.Context,
+) ([]entity.ProductMatch, error) {
+	ds := d.gdb.
+		From(schema.ProductMatch.String()).
+		Select(schema.ProductMatch.All()).
+		Order(goqu.I(schema.ProductMatch.CreatedAt()).Asc())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var matches []entity.ProductMatch
+	if err := d.db.SelectContext(ctx, &matches, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return matches, nil
+}
+
+func (d *DB) CreateRawPayload(
+	ctx context.Context,
+	payload entity.RawPayload,
+) error {
+	payload.ID = uuid.New().String()
+
+	ds := d.gdb.
+		Insert(schema.RawPayload.String()).
+		Rows(goqu.Record{
+			"id":       payload.ID,
+			"run_id":   payload.RunID,
+			"category": payload.Category,
+			"page":     payload.Page,
+			"size":     payload.Size,
+			"body":     payload.Body,
+		}).
+		OnConflict(goqu.DoNothing())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return errs.New(err)
+	}
+
+	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+func (d *DB) ListRawPayloads(
+	ctx context.Context,
+	runID string,
+	pagination db.Pagination,
+) ([]entity.RawPayload, error) {
+	ds := d.gdb.
+		From(schema.RawPayload.String()).
+		Select(schema.RawPayload.All()).
+		Where(goqu.Ex{schema.RawPayload.RunID(): runID}).
+		Order(
+			goqu.I(schema.RawPayload.Category()).Asc(),
+			goqu.I(schema.RawPayload.Page()).Asc(),
+			goqu.I(schema.RawPayload.ID()).Asc(),
+		).
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var payloads []entity.RawPayload
+	if err := d.db.SelectContext(ctx, &payloads, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return payloads, nil
+}
+
+func (d *DB) ListScrapeRuns(
+	ctx context.Context,
+	retailerID string,
+) ([]entity.ScrapeRun, error) {
+	ds := d.gdb.
+		From(schema.ScrapeRun.String()).
+		Select(schema.ScrapeRun.All()).
+		Where(goqu.Ex{schema.ScrapeRun.RetailerID(): retailerID}).
+		Order(goqu.I(schema.ScrapeRun.StartedAt()).Asc())
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var runs []entity.ScrapeRun
+	if err := d.db.SelectContext(ctx, &runs, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return runs, nil
+}
+
+// ListUnobservedProducts lists the listed products of the retailer of the
+// run that the run did not observe, among those previously observed by runs
+// of the same source. Products the latest succeeded crawl of another store
+// observed are still listed there, so they are left out.
+func (d *DB) ListUnobservedProducts(
+	ctx context.Context,
+	run entity.ScrapeRun,
+) ([]entity.Product, error) {
+	var otherStores exp.Expression = goqu.I(
+		schema.ScrapeRun.StoreID(),
+	).IsNotNull()
+	if run.StoreID != nil {
+		otherStores = goqu.Or(
+			goqu.I(schema.ScrapeRun.StoreID()).IsNull(),
+			goqu.I(schema.ScrapeRun.StoreID()).Neq(*run.StoreID),
+		)
+	}
+
+	rankedRuns := d.gdb.
+		From(schema.ScrapeRun.String()).
+		Select(
+			goqu.I(schema.ScrapeRun.ID()),
+			goqu.ROW_NUMBER().Over(
+				goqu.W().
+					PartitionBy(goqu.I(schema.ScrapeRun.StoreID())).
+					OrderBy(
+						goqu.I(schema.ScrapeRun.StartedAt()).Desc(),
+						goqu.I(schema.ScrapeRun.ID()).Desc(),
+					),
+			).As("position"),
+		).
+		Where(
+			goqu.Ex{
+				schema.ScrapeRun.RetailerID(): run.RetailerID,
+				schema.ScrapeRun.Source():     run.Source,
+				schema.ScrapeRun.Status():     entity.RunStatusSucceeded,
+			},
+			// Retries only request a few pages, leaving out most of what
+			// the store lists.
+			goqu.I(schema.ScrapeRun.Kind()).Neq(entity.RunKindRetry),
+			goqu.I(schema.ScrapeRun.ProductCount()).Gt(0),
+			otherStores,
+		)
+
+	latestRuns := d.gdb.
+		From(rankedRuns.As("ranked_runs")).
+		Select(goqu.I("ranked_runs.id")).
+		Where(goqu.Ex{"ranked_runs.position": 1})
+
+	observed := d.gdb.
+		From(schema.PriceObservation.String()).
+		Select(schema.PriceObservation.ProductID()).
+		Where(goqu.Or(
+			goqu.Ex{schema.PriceObservation.RunID(): run.ID},
+			goqu.I(schema.PriceObservation.RunID()).In(latestRuns),
+		))
+
+	observedBefore := d.gdb.
+		From(schema.PriceObservation.String()).
+		Select(schema.PriceObservation.ProductID()).
+		Where(goqu.Ex{schema.PriceObservation.Source(): run.Source})
+
+	ds := d.gdb.
+		From(schema.Product.String()).
+		Select(schema.Product.All()).
+		Where(
+			goqu.Ex{
+				schema.Product.RetailerID(): run.RetailerID,
+				schema.Product.DeletedAt():  nil,
+			},
+			goqu.I(schema.Product.ID()).NotIn(observed),
+			goqu.I(schema.Product.ID()).In(observedBefore),
+		)
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var products []entity.Product
+	if err := d.db.SelectContext(ctx, &products, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return products, nil
+}
+
+// ChangeProductListings delists or relists the products, recording the
+// change along with the run that noticed it.
+func (d *DB) ChangeProductListings(
+	ctx context.Context,
+	productIDs []string,
+	runID *string,
+	status entity.ListingStatus,
+) error {
+	const batchSize = 500
+
+	if len(productIDs) == 0 {
+		return nil
+	}
+
+	var deletedAt any
+	if status == entity.ListingStatusDelisted {
+		deletedAt = goqu.L("CURRENT_TIMESTAMP")
+	}
+
+	tx, err := d.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errs.New(err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	for i := 0; i < len(productIDs); i += batchSize {
+		end := min(i+batchSize, len(productIDs))
+
+		batch := productIDs[i:end]
+
+		ds := d.gdb.
+			Update(schema.Product.String()).
+			Set(goqu.Record{"deleted_at": deletedAt}).
+			Where(goqu.Ex{schema.Product.ID(): batch})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+
+		records := make([]goqu.Record, len(batch))
+		for i, productID := range batch {
+			records[i] = goqu.Record{
+				"id":         uuid.New().String(),
+				"product_id": productID,
+				"run_id":     runID,
+				"status":     status,
+			}
+		}
+
+		insertDs := d.gdb.
+			Insert(schema.ListingChange.String()).
+			Rows(records)
+
+		sql, args, err = insertDs.Prepared(true).ToSQL()
+		if err != nil {
+			return errs.New(err)
+		}
+
+		if _, err := tx.ExecContext(ctx, sql, args...); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errs.New(err)
+	}
+
+	return nil
+}
+
+// ListPreviousPriceObservations lists the latest observation of each product
+// made in the store by any run but the given one.
+func (d *DB) ListPreviousPriceObservations(
+	ctx context.Context,
+	productIDs []string,
+	storeID *string,
+	runID string,
+) ([]entity.PriceObservation, error) {
+	const batchSize = 500
+
+	type rankedObservation struct {
+		entity.PriceObservation
+		Recency int `db:"recency"`
+	}
+
+	observations := []entity.PriceObservation{}
+	for i := 0; i < len(productIDs); i += batchSize {
+		end := min(i+batchSize, len(productIDs))
+
+		ranked := d.gdb.
+			From(schema.PriceObservation.String()).
+			Select(
+				goqu.I(schema.PriceObservation.All()),
+				goqu.ROW_NUMBER().
+					Over(
+						goqu.W().
+							PartitionBy(goqu.I(schema.PriceObservation.ProductID())).
+							OrderBy(goqu.I(schema.PriceObservation.ObservedAt()).Desc()),
+					).
+					As("recency"),
+			).
+			Where(
+				goqu.Ex{
+					schema.PriceObservation.ProductID(): productIDs[i:end],
+					schema.PriceObservation.StoreID():   storeID,
+				},
+				goqu.Or(
+					goqu.Ex{schema.PriceObservation.RunID(): nil},
+					goqu.Ex{schema.PriceObservation.RunID(): goqu.Op{"neq": runID}},
+				),
+			)
+
+		ds := d.gdb.
+			From(ranked.As("ranked")).
+			Where(goqu.Ex{"recency": 1})
+
+		sql, args, err := ds.Prepared(true).ToSQL()
+		if err != nil {
+			return nil, errs.New(err)
+		}
+
+		var batch []rankedObservation
+		if err := d.db.SelectContext(ctx, &batch, sql, args...); err != nil {
+			return nil, errs.New(err)
+		}
+
+		for _, observation := range batch {
+			observations = append(observations, observation.PriceObservation)
+		}
+	}
+
+	return observations, nil
+}
+
+func (d *DB) ListAlertRules(ctx context.Context) ([]entity.AlertRule, error) {
+	ds := d.gdb.
+		From(schema.AlertRule.String()).
+		Select(schema.AlertRule.All()).
+		Where(goqu.Ex{schema.AlertRule.DeletedAt(): nil})
+
+	sql, args, err := ds.Prepared(true).ToSQL()
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
+	var rules []entity.AlertRule
+	if err := d.db.SelectContext(ctx, &rules, sql, args...); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return rules, nil
+}
+
+// findPage selects the page of the records ds matches into dest, and returns
+// how many records it matches in total.
+func (d *DB) findPage(
+	ctx context.Context,
+	ds *goqu.SelectDataset,
+	pagination db.Pagination,
+	dest any,
+) (int, error) {
+	countDs := ds.ClearOrder().Select(goqu.COUNT(goqu.Star()))
+	sql, args, err := countDs.Prepared(true).ToSQL()
+	if err != nil {
+		return 0, errs.New(err)
+	}
+
+	var total int
+	if err := d.db.GetContext(ctx, &total, sql, args...); err != nil {
+		return 0, errs.New(err)
+	}
+
+	pageDs := ds.
+		Limit(pagination.Limit()).
+		Offset(pagination.Offset())
+	sql, args, err = pageDs.Prepared(true).ToSQL()
+	if err != nil {
+		return 0, errs.New(err)
+	}
+
+	if err := d.db.SelectContext(ctx, dest, sql, args...); err != nil {
+		return 0, errs.New(err)
+	}
+
+	return total, nil
+}
+
+func (d *DB) FindProducts(
+	ctx context.Context,
+	filter db.ProductFilter,
+) ([]entity.Product, int, error) {
+	ds := d.gdb.
+		From(schema.Product.String()).
+		Select(schema.Product.All()).
+		Where(d.productFilters(filter)...).
+		Order(
+			goqu.I(schema.Product.Name()).Asc(),
+			goqu.I(schema.Product.ID()).Asc(),
+		)
+
+	var products []entity.Product
+	total, err := d.findPage(ctx, ds, filter.Pagination, &products)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return products, total, nil
+}
+
+func (d *DB) productFilters(filter db.ProductFilter) []exp.Expression {
+	filters := []exp.Expression{}
+	if filter.RetailerID != "" {
+		filters = append(filters, goqu.Ex{
+			schema.Product.RetailerID(): filter.RetailerID,
+		})
+	}
+	if filter.CategoryID != "" {
+		filters = append(filters, goqu.I(schema.Product.CategoryID()).In(
+			d.categoryTree(filter.CategoryID),
+		))
+	}
+	if filter.Brand != "" {
+		filters = append(filters, goqu.Ex{
+			schema.Product.Brand(): filter.Brand,
+		})
+	}
+	if filter.StoreID != "" || filter.MinPrice != nil ||
+		filter.MaxPrice != nil {
+		filters = append(
+			filters,
+			goqu.I(schema.Product.ID()).In(d.pricedProducts(filter)),
+		)
+	}
+	if !filter.IncludeDelisted {
+		filters = append(filters, goqu.Ex{schema.Product.DeletedAt(): nil})
+	}
+
+	return filters
+}
+
+// pricedProducts selects the IDs of the products with a current price at
+// the store of the filter, or at any store, within its price range.
+func (d *DB) pricedProducts(filter db.ProductFilter) *goqu.SelectDataset {
+	ds := d.gdb.
+		From(schema.ProductPrice.String()).
+		Select(schema.ProductPrice.ProductID())
+
+	if filter.StoreID != "" {
+		ds = ds.Where(goqu.Ex{schema.ProductPrice.StoreID(): filter.StoreID})
+	}
+	if filter.MinPrice != nil {
+		ds = ds.Where(
+			goqu.I(schema.ProductPrice.Price()).Gte(*filter.MinPrice),
+		)
+	}
+	if filter.MaxPrice != nil {
+		ds = ds.Where(
+			goqu.I(schema.ProductPrice.Price()).Lte(*filter.MaxPrice),
+		)
+	}
+
+	return ds
+}
+
+// categoryTree selects the IDs of the category and of its subcategories.
+func (d *DB) categoryTree(categoryID string) *goqu.SelectDataset {
+	return d.gdb.
+		From(goqu.T(schema.Category.String()).As("tree")).
+		Join(
+			goqu.T(schema.Category.String()).As("root"),
+			goqu.On(goqu.Ex{"root.retailer_id": goqu.I("tree.retailer_id")}),
+		).
+		Select(goqu.I("tree.id")).
+		Where(
+			goqu.Ex{"root.id": categoryID},
+			goqu.Or(
+				goqu.Ex{"tree.id": goqu.I("root.id")},
+				goqu.I("tree.path").Like(goqu.L(`"root"."path" || '/%'`)),
+			),
+		)
+}
+
+func (d *DB) FindPriceObservations(
+	ctx context.Context,
+	filter db.PriceObservationFilter,
+) ([]entity.PriceObservation, int, error) {
+	ds := d.gdb.
+		From(schema.PriceObservation.String()).
+		Select(schema.PriceObservation.All()).
+		Order(
+			goqu.I(schema.PriceObservation.ObservedAt()).Desc(),
+			goqu.I(schema.PriceObservation.ID()).Asc(),
+		)
+
+	if filter.ProductID != "" {
+		ds = ds.Where(goqu.Ex{
+			schema.PriceObservation.ProductID(): filter.ProductID,
+		})
+	}
+	if filter.StoreID != "" {
+		ds = ds.Where(goqu.Ex{
+			schema.PriceObservation.StoreID(): filter.StoreID,
+		})
+	}
+	if filter.From != nil {
+		ds = ds.Where(
+			goqu.I(schema.PriceObservation.ObservedAt()).
+				Gte(d.timestamp(*filter.From)),
+		)
+	}
+	if filter.To != nil {
+		ds = ds.Where(
+			goqu.I(schema.PriceObservation.ObservedAt()).
+				Lt(d.timestamp(*filter.To)),
+		)
+	}
+
+	var observations []entity.PriceObservation
+	total, err := d.findPage(ctx, ds, filter.Pagination, &observations)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return observations, total, nil
+}
+
+func (d *DB) FindCategories(
+	ctx context.Context,
+	filter db.CategoryFilter,
+) ([]entity.Category, int, error) {
+	ds := d.gdb.
+		From(schema.Category.String()).
+		Select(schema.Category.All()).
+		Where(goqu.Ex{schema.Category.DeletedAt(): nil}).
+		Order(
+			goqu.I(schema.Category.RetailerID()).Asc(),
+			goqu.I(schema.Category.Path()).Asc(),
+		)
+
+	if filter.RetailerID != "" {
+		ds = ds.Where(goqu.Ex{schema.Category.RetailerID(): filter.RetailerID})
+	}
+	if filter.ParentID != "" {
+		ds = ds.Where(goqu.Ex{schema.Category.ParentID(): filter.ParentID})
+	}
+	if filter.RootOnly {
+		ds = ds.Where(goqu.Ex{schema.Category.ParentID(): nil})
+	}
+
+	var categories []entity.Category
+	total, err := d.findPage(ctx, ds, filter.Pagination, &categories)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return categories, total, nil
+}
+
+func (d *DB) FindScrapeRuns(
+	ctx context.Context,
+	filter db.ScrapeRunFilter,
+) ([]entity.ScrapeRun, int, error) {
+	ds := d.gdb.
+		From(schema.ScrapeRun.String()).
+		Select(schema.ScrapeRun.All()).
+		Order(
+			goqu.I(schema.ScrapeRun.StartedAt()).Desc(),
+			goqu.I(schema.ScrapeRun.ID()).Asc(),
+		)
+
+	if filter.RetailerID != "" {
+		ds = ds.Where(goqu.Ex{schema.ScrapeRun.RetailerID(): filter.RetailerID})
+	}
+	if filter.StoreID != "" {
+		ds = ds.Where(goqu.Ex{schema.ScrapeRun.StoreID(): filter.StoreID})
+	}
+	if filter.Source != "" {
+		ds = ds.Where(goqu.Ex{schema.ScrapeRun.Source(): filter.Source})
+	}
+	if filter.Status != "" {
+		ds = ds.Where(goqu.Ex{schema.ScrapeRun.Status(): filter.Status})
+	}
+
+	var runs []entity.ScrapeRun
+	total, err := d.findPage(ctx, ds, filter.Pagination, &runs)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return runs, total, nil
+}
+
+func (d *DB) FindErrors(
+	ctx context.Context,
+	filter db.ErrorFilter,
+) ([]entity.Error, int, error) {
+	ds := d.gdb.
+		From(schema.Error.String()).
+		Select(schema.Error.All()).
+		Order(
+			goqu.I(schema.Error.CreatedAt()).Desc(),
+			goqu.I(schema.Error.ID()).Asc(),
+		)
+
+	if filter.RunID != "" {
+		ds = ds.Where(goqu.Ex{schema.Error.RunID(): filter.RunID})
+	}
+	if filter.Type != "" {
+		ds = ds.Where(goqu.Ex{schema.Error.Type(): filter.Type})
+	}
+	if !filter.IncludeResolved {
+		ds = ds.Where(goqu.Ex{schema.Error.DeletedAt(): nil})
+	}
+
+	var errors []entity.Error
+	total, err := d.findPage(ctx, ds, filter.Pagination, &errors)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return errors, total, nil
+}
+
+// SearchProducts finds the products matching every word of the query,
+// regardless of case and accents, in the search of the dialect. The best
+// matches come first.
+func (d *DB) SearchProducts(
+	ctx context.Context,
+	query string,
+	filter db.ProductFilter,
+) ([]entity.Product, int, error) {
+	words := normalize.Words(query)
+	if len(words) == 0 {
+		return d.FindProducts(ctx, filter)
+	}
+
+	ds := d.dialect.Search(d.gdb, words).
+		Select(schema.Product.All()).
+		Where(d.productFilters(filter)...).
+		OrderAppend(
+			goqu.I(schema.Product.Name()).Asc(),
+			goqu.I(schema.Product.ID()).Asc(),
+		)
+
+	var products []entity.Product
+	total, err := d.findPage(ctx, ds, filter.Pagination, &products)
+	if err != nil {
+		return nil, 0, errs.New(err)
+	}
+
+	return products, total, nil
+}
@@ -0,0 +1,726 @@
+package sqldb_test
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/normalize"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/postgres"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqldb"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite"
+)
+
+// The suite runs against every backend: SQLite always, which fails unless
+// the driver is built with FTS5 as make test does, and Postgres when
+// POSTGRES_TEST_DB_URL points at a database the suite can create schemas in.
+
+func TestSQLite(t *testing.T) {
+	runSuite(t, func(t *testing.T) db.DB {
+		path := filepath.Join(t.TempDir(), "test.db")
+
+		d, err := sqldb.Open(sqlite.Dialect, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = d.Close() })
+
+		migrate(t, sqlite.Dialect.Driver, path, "../../../../sql/migrations")
+
+		return d
+	})
+}
+
+func TestPostgres(t *testing.T) {
+	dbURL := os.Getenv("POSTGRES_TEST_DB_URL")
+	if dbURL == "" {
+		t.Skip("POSTGRES_TEST_DB_URL not set")
+	}
+
+	runSuite(t, func(t *testing.T) db.DB {
+		admin, err := sql.Open(postgres.Dialect.Driver, dbURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = admin.Close() })
+
+		// Every test gets a schema of its own, dropped once it is done.
+		suffix := make([]byte, 8)
+		_, _ = rand.Read(suffix)
+		schemaName := "test_" + hex.EncodeToString(suffix)
+		if _, err := admin.Exec(`CREATE SCHEMA "` + schemaName + `"`); err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() {
+			_, _ = admin.Exec(`DROP SCHEMA "` + schemaName + `" CASCADE`)
+		})
+
+		u, err := url.Parse(dbURL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		query := u.Query()
+		query.Set("search_path", schemaName)
+		u.RawQuery = query.Encode()
+
+		migrate(
+			t,
+			postgres.Dialect.Driver,
+			u.String(),
+			"../../../../sql/postgres/migrations",
+		)
+
+		d, err := sqldb.Open(postgres.Dialect, u.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = d.Close() })
+
+		return d
+	})
+}
+
+// migrate applies the migrations in dir, in the order of their timestamps.
+func migrate(t *testing.T, driver, dataSourceName, dir string) {
+	t.Helper()
+
+	conn, err := sql.Open(driver, dataSourceName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*", "migration.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range paths {
+		migration, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := conn.Exec(string(migration)); err != nil {
+			t.Fatalf("failed to apply %s: %v", path, err)
+		}
+	}
+}
+
+func runSuite(t *testing.T, open func(t *testing.T) db.DB) {
+	t.Run("products", func(t *testing.T) {
+		testProducts(t, open(t))
+	})
+	t.Run("find products", func(t *testing.T) {
+		testFindProducts(t, open(t))
+	})
+	t.Run("search products", func(t *testing.T) {
+		testSearchProducts(t, open(t))
+	})
+	t.Run("price observations", func(t *testing.T) {
+		testPriceObservations(t, open(t))
+	})
+	t.Run("scrape runs", func(t *testing.T) {
+		testScrapeRuns(t, open(t))
+	})
+	t.Run("unobserved products", func(t *testing.T) {
+		testUnobservedProducts(t, open(t))
+	})
+}
+
+const retailerID = "atacadao"
+
+// seed creates the retailer, a category tree and the products, observed at
+// their prices, returning the categories by path.
+func seed(
+	t *testing.T,
+	d db.DB,
+	products []entity.Product,
+) map[string]entity.Category {
+	t.Helper()
+	ctx := context.Background()
+
+	err := d.UpsertRetailer(ctx, entity.Retailer{ID: retailerID, Name: "Atacadão"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	drinks := "bebidas"
+	err = d.UpsertCategories(ctx, []entity.Category{
+		{RetailerID: retailerID, Name: "Bebidas", Slug: "bebidas", Path: drinks},
+		{
+			RetailerID: retailerID,
+			Name:       "Refrigerantes",
+			Slug:       "refrigerantes",
+			Path:       drinks + "/refrigerantes",
+			Level:      1,
+		},
+		{RetailerID: retailerID, Name: "Mercearia", Slug: "mercearia", Path: "mercearia"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	categories, err := d.ListCategories(ctx, retailerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	categoriesByPath := map[string]entity.Category{}
+	for _, category := range categories {
+		categoriesByPath[category.Path] = category
+	}
+
+	for i := range products {
+		products[i].RetailerID = retailerID
+		products[i].NormalizedName = normalize.Name(products[i].Name)
+		if products[i].CategoryID != nil {
+			id := categoriesByPath[*products[i].CategoryID].ID
+			products[i].CategoryID = &id
+		}
+	}
+	if err := d.CreateProducts(ctx, products); err != nil {
+		t.Fatal(err)
+	}
+
+	observations := make([]entity.PriceObservation, len(products))
+	for i, product := range products {
+		observations[i] = entity.PriceObservation{
+			ProductID: product.ID,
+			Price:     product.Price,
+			Source:    string(entity.SourceWeb),
+		}
+	}
+	if err := d.CreatePriceObservations(ctx, observations); err != nil {
+		t.Fatal(err)
+	}
+
+	return categoriesByPath
+}
+
+// catalog is a few products across the category tree, the Coca-Cola ones
+// in the subcategory and of the same brand.
+func catalog() []entity.Product {
+	return []entity.Product{
+		{
+			Name:       "Refrigerante Coca-Cola 2L",
+			Price:      9.99,
+			Brand:      ptr("Coca-Cola"),
+			Code:       ptr("1"),
+			CategoryID: ptr("bebidas/refrigerantes"),
+		},
+		{
+			Name:       "Refrigerante Coca-Cola Zero 350ml",
+			Price:      3.49,
+			Brand:      ptr("Coca-Cola"),
+			Code:       ptr("2"),
+			CategoryID: ptr("bebidas/refrigerantes"),
+		},
+		{
+			Name:       "Refrigerante Guaraná Antarctica 2L",
+			Price:      7.99,
+			Brand:      ptr("Antarctica"),
+			Code:       ptr("3"),
+			CategoryID: ptr("bebidas/refrigerantes"),
+		},
+		{
+			Name:       "Água Mineral Crystal 1,5L",
+			Price:      2.99,
+			Brand:      ptr("Crystal"),
+			Code:       ptr("4"),
+			CategoryID: ptr("bebidas"),
+		},
+		{
+			Name:       "Açúcar Refinado União 1kg",
+			Price:      4.59,
+			Brand:      ptr("União"),
+			Code:       ptr("5"),
+			CategoryID: ptr("mercearia"),
+		},
+	}
+}
+
+func testProducts(t *testing.T, d db.DB) {
+	ctx := context.Background()
+	products := catalog()
+	seed(t, d, products)
+
+	found, err := d.ListProductsByCodes(ctx, retailerID, []string{"1", "5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNames(t, found, "Açúcar Refinado União 1kg", "Refrigerante Coca-Cola 2L")
+
+	found, err = d.ListProductsByNormalizedNames(
+		ctx,
+		retailerID,
+		[]string{normalize.Name("ÁGUA  mineral crystal 1,5l")},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNames(t, found, "Água Mineral Crystal 1,5L")
+
+	// A product created again under a taken code keeps the stored one.
+	duplicate := products[0]
+	duplicate.Name = "Refrigerante Coca-Cola Original 2L"
+	duplicates := []entity.Product{duplicate}
+	if err := d.CreateProducts(ctx, duplicates); err != nil {
+		t.Fatal(err)
+	}
+	if duplicates[0].ID != products[0].ID {
+		t.Fatalf("got ID %s, want the stored %s", duplicates[0].ID, products[0].ID)
+	}
+	found, err = d.ListProductsByCodes(ctx, retailerID, []string{*duplicate.Code})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNames(t, found, duplicate.Name)
+
+	product := products[0]
+	product.Price = 8.49
+	if err := d.UpdateProducts(ctx, []entity.Product{product}); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err = d.ListProductsByIDs(ctx, []string{product.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Price != 8.49 {
+		t.Fatalf("got %+v, want the updated price 8.49", found)
+	}
+
+	err = d.ChangeProductListings(
+		ctx,
+		[]string{product.ID},
+		nil,
+		entity.ListingStatusDelisted,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	listed, total, err := d.FindProducts(ctx, db.ProductFilter{
+		Pagination: db.Pagination{Page: 1, PageSize: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != len(products)-1 || len(listed) != total {
+		t.Fatalf("got %d listed products, want %d", total, len(products)-1)
+	}
+
+	// A delisted product is still found by code, so it can be relisted.
+	found, err = d.ListProductsByCodes(ctx, retailerID, []string{"1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].DeletedAt == nil {
+		t.Fatalf("got %+v, want the delisted product", found)
+	}
+}
+
+func testFindProducts(t *testing.T, d db.DB) {
+	ctx := context.Background()
+	products := catalog()
+	categories := seed(t, d, products)
+
+	err := d.UpsertStores(ctx, []entity.Store{
+		{RetailerID: retailerID, Code: "a", Name: "A"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores, err := d.ListStores(ctx, retailerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeID := stores[0].ID
+
+	// The Coca-Cola 2L is cheaper at store A, where it was observed twice,
+	// the latest observation giving its current price.
+	for _, price := range []float64{8.99, 5.49, 5.99} {
+		err := d.CreatePriceObservations(ctx, []entity.PriceObservation{{
+			ProductID: products[0].ID,
+			Price:     price,
+			Source:    string(entity.SourceAPI),
+			StoreID:   &storeID,
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter db.ProductFilter
+		want   []string
+	}{
+		{
+			name:   "brand",
+			filter: db.ProductFilter{Brand: "Coca-Cola"},
+			want: []string{
+				"Refrigerante Coca-Cola 2L",
+				"Refrigerante Coca-Cola Zero 350ml",
+			},
+		},
+		{
+			name:   "category with its subcategories",
+			filter: db.ProductFilter{CategoryID: categories["bebidas"].ID},
+			want: []string{
+				"Água Mineral Crystal 1,5L",
+				"Refrigerante Coca-Cola 2L",
+				"Refrigerante Coca-Cola Zero 350ml",
+				"Refrigerante Guaraná Antarctica 2L",
+			},
+		},
+		{
+			name:   "price range at any store",
+			filter: db.ProductFilter{MinPrice: ptr(3.0), MaxPrice: ptr(8.0)},
+			want: []string{
+				"Açúcar Refinado União 1kg",
+				"Refrigerante Coca-Cola 2L",
+				"Refrigerante Coca-Cola Zero 350ml",
+				"Refrigerante Guaraná Antarctica 2L",
+			},
+		},
+		{
+			name: "price range at a store",
+			filter: db.ProductFilter{
+				StoreID:  storeID,
+				MinPrice: ptr(5.5),
+				MaxPrice: ptr(6.0),
+			},
+			want: []string{"Refrigerante Coca-Cola 2L"},
+		},
+		{
+			name: "earlier price at a store",
+			filter: db.ProductFilter{
+				StoreID:  storeID,
+				MaxPrice: ptr(5.5),
+			},
+			want: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Pagination = db.Pagination{Page: 1, PageSize: 10}
+
+			products, total, err := d.FindProducts(ctx, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != len(tt.want) {
+				t.Errorf("got a total of %d, want %d", total, len(tt.want))
+			}
+			assertNames(t, products, tt.want...)
+		})
+	}
+
+	prices, err := d.ListProductPrices(ctx, []string{products[0].ID}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prices) != 2 || prices[0].Price != 5.99 || prices[1].Price != 9.99 {
+		t.Errorf("got prices %+v, want 5.99 at store A and 9.99", prices)
+	}
+}
+
+func testSearchProducts(t *testing.T, d db.DB) {
+	ctx := context.Background()
+	categories := seed(t, d, catalog())
+
+	tests := []struct {
+		name   string
+		query  string
+		filter db.ProductFilter
+		want   []string
+	}{
+		{
+			name:  "every word regardless of case and accents",
+			query: "REFRIGERANTE guarana",
+			want:  []string{"Refrigerante Guaraná Antarctica 2L"},
+		},
+		{
+			name:   "brand",
+			query:  "refrigerante",
+			filter: db.ProductFilter{Brand: "Coca-Cola"},
+			want: []string{
+				"Refrigerante Coca-Cola 2L",
+				"Refrigerante Coca-Cola Zero 350ml",
+			},
+		},
+		{
+			name:   "category",
+			query:  "refrigerante",
+			filter: db.ProductFilter{CategoryID: categories["mercearia"].ID},
+			want:   []string{},
+		},
+		{
+			name:   "category with its subcategories",
+			query:  "crystal",
+			filter: db.ProductFilter{CategoryID: categories["bebidas"].ID},
+			want:   []string{"Água Mineral Crystal 1,5L"},
+		},
+		{
+			name:  "empty query",
+			query: " ",
+			filter: db.ProductFilter{
+				CategoryID: categories["mercearia"].ID,
+			},
+			want: []string{"Açúcar Refinado União 1kg"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.filter.Pagination = db.Pagination{Page: 1, PageSize: 10}
+
+			products, total, err := d.SearchProducts(ctx, tt.query, tt.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != len(tt.want) {
+				t.Errorf("got a total of %d, want %d", total, len(tt.want))
+			}
+			assertNames(t, products, tt.want...)
+		})
+	}
+}
+
+func testPriceObservations(t *testing.T, d db.DB) {
+	ctx := context.Background()
+	products := catalog()
+	seed(t, d, products)
+
+	filter := db.PriceObservationFilter{
+		Pagination: db.Pagination{Page: 1, PageSize: 10},
+		ProductID:  products[0].ID,
+	}
+	observations, _, err := d.FindPriceObservations(ctx, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(observations) != 1 {
+		t.Fatalf("got %d observations, want 1", len(observations))
+	}
+
+	// The bounds are given in another time zone than the stored UTC.
+	zone := time.FixedZone("BRT", -3*60*60)
+	observedAt := observations[0].ObservedAt.In(zone)
+
+	tests := []struct {
+		name string
+		from time.Time
+		to   time.Time
+		want int
+	}{
+		{
+			name: "around the observation",
+			from: observedAt.Add(-time.Second),
+			to:   observedAt.Add(time.Second),
+			want: 1,
+		},
+		{
+			name: "after the observation",
+			from: observedAt.Add(time.Second),
+			to:   observedAt.Add(time.Hour),
+			want: 0,
+		},
+		{
+			name: "before the observation",
+			from: observedAt.Add(-time.Hour),
+			to:   observedAt,
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter.From, filter.To = &tt.from, &tt.to
+
+			found, total, err := d.FindPriceObservations(ctx, filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if total != tt.want || len(found) != tt.want {
+				t.Errorf("got %d observations, want %d", total, tt.want)
+			}
+		})
+	}
+}
+
+func testScrapeRuns(t *testing.T, d db.DB) {
+	ctx := context.Background()
+	seed(t, d, nil)
+
+	run := entity.ScrapeRun{
+		RetailerID:     retailerID,
+		Source:         string(entity.SourceAPI),
+		Kind:           string(entity.RunKindCrawl),
+		Status:         string(entity.RunStatusRunning),
+		PageSize:       ptr(50),
+		CategoryCounts: "{}",
+	}
+	if err := d.CreateScrapeRun(ctx, &run); err != nil {
+		t.Fatal(err)
+	}
+
+	run.Status = string(entity.RunStatusFailed)
+	if err := d.FinishScrapeRun(ctx, run); err != nil {
+		t.Fatal(err)
+	}
+
+	run.Status = string(entity.RunStatusRunning)
+	if err := d.ReopenScrapeRun(ctx, run); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := d.GetScrapeRun(ctx, run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != run.Status || got.FinishedAt != nil ||
+		got.PageSize == nil || *got.PageSize != 50 {
+		t.Fatalf("got %+v, want a running run of pages of 50", got)
+	}
+
+	run.Status = string(entity.RunStatusSucceeded)
+	run.ProductCount = 5
+	if err := d.FinishScrapeRun(ctx, run); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err = d.GetScrapeRun(ctx, run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Status != run.Status || got.ProductCount != 5 ||
+		got.FinishedAt == nil {
+		t.Fatalf("got %+v, want a finished run", got)
+	}
+
+	runs, total, err := d.FindScrapeRuns(ctx, db.ScrapeRunFilter{
+		Pagination: db.Pagination{Page: 1, PageSize: 10},
+		Status:     string(entity.RunStatusSucceeded),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 1 || len(runs) != 1 || runs[0].ID != run.ID {
+		t.Fatalf("got %+v, want the finished run", runs)
+	}
+}
+
+func testUnobservedProducts(t *testing.T, d db.DB) {
+	ctx := context.Background()
+	products := catalog()[:3]
+	seed(t, d, products)
+
+	err := d.UpsertStores(ctx, []entity.Store{
+		{RetailerID: retailerID, Code: "a", Name: "A"},
+		{RetailerID: retailerID, Code: "b", Name: "B"},
+		{RetailerID: retailerID, Code: "c", Name: "C"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stores, err := d.ListStores(ctx, retailerID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	storeIDs := map[string]string{}
+	for _, store := range stores {
+		storeIDs[store.Code] = store.ID
+	}
+
+	// observe records a run of the store observing the products.
+	observe := func(
+		store string,
+		kind entity.RunKind,
+		status entity.RunStatus,
+		observed ...entity.Product,
+	) entity.ScrapeRun {
+		t.Helper()
+
+		run := entity.ScrapeRun{
+			RetailerID:     retailerID,
+			StoreID:        ptr(storeIDs[store]),
+			Source:         string(entity.SourceAPI),
+			Kind:           string(kind),
+			Status:         string(entity.RunStatusRunning),
+			CategoryCounts: "{}",
+		}
+		if err := d.CreateScrapeRun(ctx, &run); err != nil {
+			t.Fatal(err)
+		}
+
+		observations := make([]entity.PriceObservation, len(observed))
+		for i, product := range observed {
+			observations[i] = entity.PriceObservation{
+				ProductID: product.ID,
+				Price:     product.Price,
+				Source:    run.Source,
+				RunID:     &run.ID,
+				StoreID:   run.StoreID,
+			}
+		}
+		if err := d.CreatePriceObservations(ctx, observations); err != nil {
+			t.Fatal(err)
+		}
+
+		run.Status = string(status)
+		run.ProductCount = len(observed)
+		if err := d.FinishScrapeRun(ctx, run); err != nil {
+			t.Fatal(err)
+		}
+
+		return run
+	}
+
+	// Store A still lists the first two products, even if its later retry
+	// only requested the first, and store C, whose run did not succeed, the
+	// third, so only the third is gone.
+	crawl, retry := entity.RunKindCrawl, entity.RunKindRetry
+	succeeded := entity.RunStatusSucceeded
+	observe("a", crawl, succeeded, products[0], products[1])
+	observe("a", retry, succeeded, products[0])
+	observe("c", crawl, entity.RunStatusPartial, products[2])
+	observe("b", crawl, succeeded, products...)
+	run := observe("b", crawl, succeeded, products[0])
+
+	unobserved, err := d.ListUnobservedProducts(ctx, run)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNames(t, unobserved, products[2].Name)
+}
+
+// assertNames checks the products are the named ones, in any order.
+func assertNames(t *testing.T, products []entity.Product, want ...string) {
+	t.Helper()
+
+	got := make([]string, len(products))
+	for i, product := range products {
+		got[i] = product.Name
+	}
+	slices.Sort(got)
+	slices.Sort(want)
+
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
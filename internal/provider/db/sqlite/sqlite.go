@@ -1,187 +1,97 @@
 package sqlite
 
 import (
-	"context"
+	"errors"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/doug-martin/goqu/v9"
-	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
-	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
-	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
-	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqlite/schema"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/schema"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/db/sqldb"
 )
 
-type DB struct {
-	db  *sqlx.DB
-	gdb *goqu.Database
+// productsFTS is the full-text index of the products, keyed by the IDs of
+// product_search_ids. Prisma does not support virtual tables, so it is not
+// part of the schema.
+const productsFTS = "products_fts"
+
+// Dialect opens SQLite databases through the sqlite3 driver.
+var Dialect = sqldb.Dialect{
+	Driver:    "sqlite3",
+	Name:      "sqlite3",
+	Check:     check,
+	Search:    search,
+	Timestamp: timestamp,
 }
 
 func New(
 	e *env.Env,
-) *DB {
-	db, err := sqlx.Open("sqlite3", e.SQLiteDBPath)
+) *sqldb.DB {
+	db, err := sqldb.Open(Dialect, e.SQLiteDBPath)
 	if err != nil {
 		log.Fatalf("failed to open database: %v", err)
 	}
 
-	gdb := goqu.New("sqlite3", db.DB)
-
-	return &DB{
-		db:  db,
-		gdb: gdb,
-	}
-}
-
-func (d *DB) Close() error {
-	return d.db.Close()
-}
-
-func (d *DB) CreateProducts(
-	ctx context.Context,
-	products []entity.Product,
-) error {
-	const batchSize = 500
-
-	if len(products) == 0 {
-		return nil
-	}
-
-	for i := 0; i < len(products); i += batchSize {
-		end := min(i+batchSize, len(products))
-
-		batch := products[i:end]
-		var records []goqu.Record
-
-		for i := range batch {
-			batch[i].ID = uuid.New().String()
-
-			record := goqu.Record{
-				"id":    batch[i].ID,
-				"name":  batch[i].Name,
-				"price": batch[i].Price,
-				"code":  batch[i].Code,
-			}
-
-			records = append(records, record)
-		}
-
-		ds := d.gdb.Insert(schema.Product.String()).Rows(records)
-		sql, args, err := ds.Prepared(true).ToSQL()
-		if err != nil {
-			return errs.New(err)
-		}
-
-		if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
-			return errs.New(err)
-		}
-	}
-
-	return nil
-}
-
-func (d *DB) CreateError(
-	ctx context.Context,
-	errRec entity.Error,
-) error {
-	errRec.ID = uuid.New().String()
-
-	ds := d.gdb.Insert(schema.Error.String()).Rows(goqu.Record{
-		schema.Error.ID():         errRec.ID,
-		schema.Error.Message():    errRec.Message,
-		schema.Error.Type():       errRec.Type,
-		schema.Error.StackTrace(): errRec.StackTrace,
-		schema.Error.Metadata():   errRec.Metadata,
-	})
-	sql, args, err := ds.Prepared(true).ToSQL()
-	if err != nil {
-		return errs.New(err)
-	}
-
-	if _, err := d.db.ExecContext(ctx, sql, args...); err != nil {
-		return errs.New(err)
-	}
-
-	return nil
+	return db
 }
 
-func (d *DB) ListErrorsByType(
-	ctx context.Context,
-	errType errs.ErrType,
-) ([]entity.Error, error) {
-	ds := d.gdb.
-		From(schema.Error.String()).
-		Select(schema.Error.All()).
-		Where(
-			goqu.Ex{
-				schema.Error.DeletedAt(): nil,
-				schema.Error.Type():      string(errType),
-			},
-		).
-		Order(goqu.C(schema.Error.CreatedAt()).Desc())
-
-	sql, args, err := ds.Prepared(true).ToSQL()
-	if err != nil {
-		return nil, errs.New(err)
-	}
-
-	var errors []entity.Error
-	if err := d.db.SelectContext(ctx, &errors, sql, args...); err != nil {
-		return nil, errs.New(err)
-	}
+// timestampLayout is how SQLite writes CURRENT_TIMESTAMP, in UTC.
+const timestampLayout = "2006-01-02 15:04:05"
 
-	return errors, nil
+// timestamp formats t as SQLite writes CURRENT_TIMESTAMP, as timestamps are
+// stored as text and compared as such.
+func timestamp(t time.Time) any {
+	return t.UTC().Format(timestampLayout)
 }
 
-func (d *DB) DeleteErrors(
-	ctx context.Context,
-	ids []string,
-) error {
-	ds := d.gdb.
-		Update(schema.Error.String()).
-		Set(goqu.Record{schema.Error.DeletedAt(): time.Now()}).
-		Where(goqu.Ex{schema.Error.ID(): ids})
-
-	sql, args, err := ds.Prepared(true).ToSQL()
+// check fails unless the driver includes FTS5, which the driver only
+// includes when built with the sqlite_fts5 tag. Not only the product search
+// needs it: the triggers of the migrations write every product to the
+// products_fts index too.
+func check(db *sqlx.DB) error {
+	var hasFTS5 bool
+	err := db.Get(&hasFTS5, "SELECT sqlite_compileoption_used('ENABLE_FTS5')")
 	if err != nil {
-		return errs.New(err)
+		return err
 	}
-
-	_, err = d.db.ExecContext(ctx, sql, args...)
-	if err != nil {
-		return errs.New(err)
+	if !hasFTS5 {
+		return errors.New(
+			"SQLite driver built without FTS5, build with -tags sqlite_fts5 " +
+				"or through make, which sets it",
+		)
 	}
 
 	return nil
 }
 
-func (d *DB) ListProductsByNames(
-	ctx context.Context,
-	names []string,
-) ([]entity.Product, error) {
-	ds := d.gdb.
-		From(schema.Product.String()).
-		Select(schema.Product.All()).
-		Where(
-			goqu.Ex{
-				schema.Product.DeletedAt(): nil,
-				schema.Product.Name():      names,
-			},
-		)
-
-	sql, args, err := ds.Prepared(true).ToSQL()
-	if err != nil {
-		return nil, errs.New(err)
-	}
-
-	var products []entity.Product
-	if err := d.db.SelectContext(ctx, &products, sql, args...); err != nil {
-		return nil, errs.New(err)
+// search finds the products through the products_fts full-text index,
+// every word matching the start of a word of the name, brand or category.
+func search(gdb *goqu.Database, words []string) *goqu.SelectDataset {
+	// Every word is quoted as a prefix, so none is read as an operator.
+	terms := make([]string, len(words))
+	for i, word := range words {
+		terms[i] = `"` + word + `"*`
 	}
 
-	return products, nil
+	return gdb.
+		From(productsFTS).
+		Join(
+			goqu.T(schema.ProductSearchID.String()),
+			goqu.On(goqu.Ex{
+				schema.ProductSearchID.ID(): goqu.I(productsFTS + ".rowid"),
+			}),
+		).
+		Join(
+			goqu.T(schema.Product.String()),
+			goqu.On(goqu.Ex{
+				schema.Product.ID(): goqu.I(schema.ProductSearchID.ProductID()),
+			}),
+		).
+		Where(goqu.L(`"`+productsFTS+`" MATCH ?`, strings.Join(terms, " "))).
+		Order(goqu.I(productsFTS + ".rank").Asc())
 }
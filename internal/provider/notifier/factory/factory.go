@@ -0,0 +1,21 @@
+package factory
+
+import (
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier/stdout"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier/webhook"
+)
+
+// NewNotifier creates the alert notifier selected by NOTIFIER.
+func NewNotifier(
+	e *env.Env,
+) notifier.Notifier {
+	switch e.Notifier {
+	case env.NotifierWebhook:
+		return webhook.New(e)
+
+	default:
+		return stdout.New()
+	}
+}
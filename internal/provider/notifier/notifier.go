@@ -0,0 +1,28 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+)
+
+// Event is a change of a product that matched an alert rule.
+type Event struct {
+	Type     entity.AlertEvent `json:"type"`
+	RuleID   string            `json:"rule_id"`
+	RuleName string            `json:"rule_name"`
+	Product  entity.Product    `json:"product"`
+	RunID    *string           `json:"run_id,omitempty"`
+	StoreID  *string           `json:"store_id,omitempty"`
+	// PreviousPrice and ChangePercent are only set on price changes.
+	PreviousPrice *float64  `json:"previous_price,omitempty"`
+	Price         float64   `json:"price,omitempty"`
+	ChangePercent *float64  `json:"change_percent,omitempty"`
+	OccurredAt    time.Time `json:"occurred_at"`
+}
+
+// Notifier delivers alert events to whoever watches them.
+type Notifier interface {
+	Notify(ctx context.Context, events []Event) error
+}
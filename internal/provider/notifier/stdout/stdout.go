@@ -0,0 +1,33 @@
+package stdout
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier"
+)
+
+// Notifier prints every event to the standard output as a line of JSON.
+type Notifier struct {
+	enc *json.Encoder
+}
+
+func New() *Notifier {
+	return &Notifier{
+		enc: json.NewEncoder(os.Stdout),
+	}
+}
+
+func (n *Notifier) Notify(_ context.Context, events []notifier.Event) error {
+	for _, event := range events {
+		if err := n.enc.Encode(event); err != nil {
+			return errs.New(err)
+		}
+	}
+
+	return nil
+}
+
+var _ notifier.Notifier = (*Notifier)(nil)
@@ -0,0 +1,65 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+
+	"resty.dev/v3"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/provider/notifier"
+)
+
+// Notifier posts the events as JSON to the configured webhook URL, all the
+// events of a call in a single request.
+type Notifier struct {
+	c   *resty.Client
+	url string
+}
+
+func New(
+	e *env.Env,
+) *Notifier {
+	c := resty.New().
+		SetTimeout(e.HTTPTimeout).
+		SetRetryCount(*e.HTTPRetryCount).
+		SetRetryWaitTime(e.HTTPRetryWaitTime).
+		SetRetryMaxWaitTime(e.HTTPRetryMaxWaitTime).
+		// Events are posted, which resty only retries when allowed to. A
+		// retried request may deliver the events twice, which beats losing
+		// them.
+		SetAllowNonIdempotentRetry(true)
+
+	return &Notifier{
+		c:   c,
+		url: e.AlertWebhookURL,
+	}
+}
+
+type payload struct {
+	Events []notifier.Event `json:"events"`
+}
+
+func (n *Notifier) Notify(ctx context.Context, events []notifier.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	res, err := n.c.R().
+		SetContext(ctx).
+		SetBody(payload{Events: events}).
+		Post(n.url)
+	if err != nil {
+		return errs.New(err)
+	}
+	if res.IsError() {
+		return errs.New(
+			fmt.Sprintf("error response: %s", res.String()),
+		)
+	}
+
+	return nil
+}
+
+var _ notifier.Notifier = (*Notifier)(nil)
@@ -3,95 +3,293 @@ package atacadaoapi
 import (
 	"cmp"
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math"
+	"net"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
 	"strconv"
-	"sync"
+	"strings"
 
 	"github.com/danielmesquitta/supermarket-scraper/internal/config/env"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
 	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+	"github.com/danielmesquitta/supermarket-scraper/internal/pkg/ratelimit"
 	"github.com/danielmesquitta/supermarket-scraper/internal/provider/supermarketapi"
 	"golang.org/x/sync/errgroup"
 	"resty.dev/v3"
 )
 
+const (
+	RetailerID   = "atacadao"
+	RetailerName = "Atacadão"
+)
+
 type AtacadaoAPI struct {
-	c *resty.Client
+	c                   *resty.Client
+	gate                *ratelimit.Gate
+	stores              []string
+	salesChannel        string
+	categoryConcurrency int
+	pageConcurrency     int
+	pageSize            int
 }
 
 func New(
 	e *env.Env,
+	gate *ratelimit.Gate,
 ) *AtacadaoAPI {
-	c := resty.New().SetBaseURL(e.AtacadaoAPIBaseURL)
+	c := resty.New().
+		SetBaseURL(e.AtacadaoAPIBaseURL).
+		SetTimeout(e.HTTPTimeout).
+		SetRetryCount(*e.HTTPRetryCount).
+		SetRetryWaitTime(e.HTTPRetryWaitTime).
+		SetRetryMaxWaitTime(e.HTTPRetryMaxWaitTime).
+		AddRetryConditions(isNetworkError)
+
+	// Every category shares the same gate and limiter, so the crawl as a
+	// whole never exceeds the configured rate, whatever its concurrency.
+	c.SetTransport(
+		ratelimit.NewTransport(c.Transport(), gate, e.HTTPRequestsPerSecond),
+	)
 
 	return &AtacadaoAPI{
-		c: c,
+		c:                   c,
+		gate:                gate,
+		stores:              e.AtacadaoStores,
+		salesChannel:        e.AtacadaoSalesChannel,
+		categoryConcurrency: e.APICategoryConcurrency,
+		pageConcurrency:     e.APIPageConcurrency,
+		pageSize:            e.APIPageSize,
 	}
 }
 
-var categories = []string{
-	"bebidas",
-	"mercearia",
-	"limpeza",
-	"higiene-e-perfumaria",
-	"padaria-e-matinais",
-	"papelaria",
-	"pet-shop",
-	"automotivo",
-	"eletronicos-e-eletroportateis",
-	"vestuario",
-	"utilidades-domesticas",
-	"descartaveis-e-embalagens",
-	"esporte-e-lazer",
+// isNetworkError complements resty's default retry conditions, which
+// already cover 429 and 5xx responses and honour their Retry-After header,
+// with connection failures such as refused or reset connections.
+func isNetworkError(_ *resty.Response, err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func (a *AtacadaoAPI) Retailer() entity.Retailer {
+	return entity.Retailer{
+		ID:   RetailerID,
+		Name: RetailerName,
+	}
+}
+
+// regionsPath is the checkout endpoint that lists the sellers delivering to
+// a postal code, relative to the host of the API.
+const regionsPath = "/api/checkout/pub/regions"
+
+var postalCodeRegexp = regexp.MustCompile(`^\d{5}-?\d{3}$`)
+
+// ListStores resolves the configured stores, each either a seller code such
+// as "atacadaobr30" or a postal code such as "01310-100", in which case the
+// store is the first seller delivering to it.
+func (a *AtacadaoAPI) ListStores(ctx context.Context) ([]entity.Store, error) {
+	stores := []entity.Store{}
+	for _, store := range a.stores {
+		if !postalCodeRegexp.MatchString(store) {
+			stores = append(stores, entity.Store{
+				RetailerID: RetailerID,
+				Code:       store,
+				Name:       store,
+				RegionID: base64.StdEncoding.EncodeToString(
+					[]byte("SW#" + store),
+				),
+			})
+			continue
+		}
+
+		postalCode := strings.ReplaceAll(store, "-", "")
+
+		res, err := a.c.R().
+			SetContext(ctx).
+			SetQueryParams(map[string]string{
+				"country":    "BRA",
+				"postalCode": postalCode,
+			}).
+			Get(a.hostURL(regionsPath))
+		if err != nil {
+			return nil, errs.New(err)
+		}
+		if res.IsError() {
+			return nil, errs.New(
+				fmt.Sprintf("error response: %s", res.String()),
+			)
+		}
+
+		type Seller struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}
+
+		type Region struct {
+			ID      string   `json:"id"`
+			Sellers []Seller `json:"sellers"`
+		}
+
+		var regions []Region
+		if err := json.Unmarshal(res.Bytes(), &regions); err != nil {
+			return nil, errs.New(err)
+		}
+		if len(regions) == 0 || len(regions[0].Sellers) == 0 {
+			return nil, errs.New(
+				fmt.Sprintf("no store delivers to postal code %s", store),
+			)
+		}
+
+		seller := regions[0].Sellers[0]
+		stores = append(stores, entity.Store{
+			RetailerID: RetailerID,
+			Code:       seller.ID,
+			Name:       cmp.Or(seller.Name, seller.ID),
+			RegionID:   regions[0].ID,
+			PostalCode: &postalCode,
+		})
+	}
+
+	return stores, nil
+}
+
+// hostURL resolves endpoint against the host of the API.
+func (a *AtacadaoAPI) hostURL(endpoint string) string {
+	baseURL, err := url.Parse(a.c.BaseURL())
+	if err != nil {
+		return endpoint
+	}
+	return baseURL.ResolveReference(&url.URL{Path: endpoint}).String()
+}
+
+// categoryTreePath is the catalog endpoint that lists the category tree,
+// relative to the host of the API, down to three levels deep.
+const categoryTreePath = "/api/catalog_system/pub/category/tree/3"
+
+func (a *AtacadaoAPI) ListCategories(
+	ctx context.Context,
+) ([]supermarketapi.Category, error) {
+	res, err := a.c.R().
+		SetContext(ctx).
+		Get(a.hostURL(categoryTreePath))
+	if err != nil {
+		return nil, errs.New(err)
+	}
+	if res.IsError() {
+		return nil, errs.New(
+			fmt.Sprintf("error response: %s", res.String()),
+		)
+	}
+
+	type Node struct {
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		Children []Node `json:"children"`
+	}
+
+	var nodes []Node
+	if err := json.Unmarshal(res.Bytes(), &nodes); err != nil {
+		return nil, errs.New(err)
+	}
+
+	var toCategories func(nodes []Node) []supermarketapi.Category
+	toCategories = func(nodes []Node) []supermarketapi.Category {
+		categories := []supermarketapi.Category{}
+		for _, node := range nodes {
+			nodeURL, err := url.Parse(node.URL)
+			if err != nil {
+				continue
+			}
+			slug := path.Base(nodeURL.Path)
+			if slug == "" || slug == "/" || slug == "." {
+				continue
+			}
+
+			categories = append(categories, supermarketapi.Category{
+				Name:     node.Name,
+				Slug:     slug,
+				Children: toCategories(node.Children),
+			})
+		}
+		return categories
+	}
+
+	categories := toCategories(nodes)
+	if len(categories) == 0 {
+		return nil, errs.New("no categories found")
+	}
+
+	return categories, nil
 }
 
 func (a *AtacadaoAPI) ListProducts(
 	ctx context.Context,
-) ([]entity.Product, error) {
-	products := []entity.Product{}
+	opts supermarketapi.ListProductsOptions,
+) error {
+	progress := supermarketapi.NewProgress(opts.Checkpoints)
 
-	mu := &sync.Mutex{}
-	g := errgroup.Group{}
-	g.SetLimit(5)
-	for _, category := range categories {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(a.categoryConcurrency)
+	for _, category := range opts.Categories {
 		g.Go(func() error {
-			return a.bulkRequests(ctx, mu, &products, category)
+			return a.bulkRequests(ctx, progress, &opts, category)
 		})
 	}
 
 	if err := g.Wait(); err != nil {
-		return nil, errs.New(err)
+		return errs.New(err)
 	}
 
-	return products, nil
+	return nil
 }
 
 func (a *AtacadaoAPI) bulkRequests(
 	ctx context.Context,
-	mu *sync.Mutex,
-	products *[]entity.Product,
+	progress *supermarketapi.Progress,
+	opts *supermarketapi.ListProductsOptions,
 	category string,
 ) error {
-	defaultOpts := requestOptions{
-		Page:     1,
-		Size:     100,
-		Total:    0,
+	firstPage := supermarketapi.PageRequest{
+		Store:    opts.Store,
 		Category: category,
+		Page:     1,
+		Size:     a.pageSize,
 	}
-	err := a.doRequest(ctx, mu, products, &defaultOpts)
-	if err != nil {
-		return err
+	if opts.PageSize > 0 {
+		firstPage.Size = opts.PageSize
+	}
+
+	totalPages := progress.TotalPages(category)
+	if !progress.Done(category, firstPage.Page) {
+		page, err := a.requestPage(ctx, opts, firstPage)
+		if err != nil {
+			return errs.New(err)
+		}
+		if page == nil {
+			return nil
+		}
+		totalPages = page.TotalPages
 	}
 
-	g := errgroup.Group{}
-	g.SetLimit(10)
-	for i := 2; i <= defaultOpts.Total; i++ {
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(a.pageConcurrency)
+	for i := 2; i <= totalPages; i++ {
+		if progress.Done(category, i) {
+			continue
+		}
+
 		g.Go(func() error {
-			opts := defaultOpts
-			opts.Page = i
-			return a.doRequest(ctx, mu, products, &opts)
+			req := firstPage
+			req.Page = i
+			_, err := a.requestPage(ctx, opts, req)
+			return err
 		})
 	}
 
@@ -102,54 +300,94 @@ func (a *AtacadaoAPI) bulkRequests(
 	return nil
 }
 
-type requestOptions struct {
-	Page     int
-	Size     int
-	Total    int
-	Category string
+// requestPage fetches a page and hands it to opts.OnPage. A page that cannot
+// be fetched is reported to opts.OnPageError and nil is returned, unless the
+// request budget ran out, which stops the crawl.
+func (a *AtacadaoAPI) requestPage(
+	ctx context.Context,
+	opts *supermarketapi.ListProductsOptions,
+	req supermarketapi.PageRequest,
+) (*supermarketapi.Page, error) {
+	page, err := a.ListProductsPage(ctx, req)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, errs.New(ctxErr)
+		}
+		if a.gate.Exhausted() {
+			return nil, errs.New(ratelimit.ErrBudgetExhausted)
+		}
+		if opts.OnPageError != nil {
+			opts.OnPageError(ctx, req, err)
+		}
+		return nil, nil
+	}
+
+	if err := opts.OnPage(ctx, *page); err != nil {
+		return nil, errs.New(err)
+	}
+
+	return page, nil
 }
 
-func (a *AtacadaoAPI) doRequest(
+func (a *AtacadaoAPI) ListProductsPage(
 	ctx context.Context,
-	mu *sync.Mutex,
-	products *[]entity.Product,
-	opts *requestOptions,
-) error {
-	queryParams, err := buildQueryParams(opts.Page, opts.Size, opts.Category)
+	req supermarketapi.PageRequest,
+) (*supermarketapi.Page, error) {
+	queryParams, err := a.buildQueryParams(req)
 	if err != nil {
-		return errs.New(err)
+		return nil, errs.New(err)
 	}
 	res, err := a.c.R().
 		SetContext(ctx).
 		SetQueryParams(queryParams).
 		Get("/")
 	if err != nil {
-		return errs.New(err)
+		return nil, errs.New(err)
 	}
 	if res.IsError() {
-		return errs.New(
+		return nil, errs.New(
 			fmt.Sprintf("error response: %s", res.String()),
 		)
 	}
-	response, err := parseResponse(res)
+
+	page, err := a.ParsePage(req, res.Bytes())
 	if err != nil {
-		return errs.New(err)
+		return nil, errs.New(err)
 	}
 
-	totalPages := math.Ceil(float64(response.TotalCount) / float64(opts.Size))
-	opts.Total = int(totalPages)
+	return page, nil
+}
 
-	mu.Lock()
-	*products = append(*products, response.Products...)
-	mu.Unlock()
+func (a *AtacadaoAPI) ParsePage(
+	req supermarketapi.PageRequest,
+	raw []byte,
+) (*supermarketapi.Page, error) {
+	response, err := parseResponse(raw)
+	if err != nil {
+		return nil, errs.New(err)
+	}
 
-	return nil
+	totalPages := math.Ceil(float64(response.TotalCount) / float64(req.Size))
+
+	for i := range response.Products {
+		if slug := response.Products[i].Slug; slug != nil {
+			productURL := a.hostURL("/" + *slug + "/p")
+			response.Products[i].URL = &productURL
+		}
+	}
+
+	return &supermarketapi.Page{
+		PageRequest: req,
+		TotalPages:  int(totalPages),
+		Products:    response.Products,
+		Raw:         raw,
+	}, nil
 }
 
-func buildQueryParams(
-	page, size int,
-	category string,
+func (a *AtacadaoAPI) buildQueryParams(
+	req supermarketapi.PageRequest,
 ) (map[string]string, error) {
+	page, size := req.Page, req.Size
 	if page < 1 {
 		page = 1
 	}
@@ -157,6 +395,21 @@ func buildQueryParams(
 		size = 20
 	}
 
+	type Channel struct {
+		SalesChannel string `json:"salesChannel"`
+		Seller       string `json:"seller"`
+		RegionID     string `json:"regionId"`
+	}
+
+	channel, err := json.Marshal(Channel{
+		SalesChannel: a.salesChannel,
+		Seller:       req.Store.Code,
+		RegionID:     req.Store.RegionID,
+	})
+	if err != nil {
+		return nil, errs.New(err)
+	}
+
 	type SelectedFacet struct {
 		Key   string `json:"key"`
 		Value string `json:"value"`
@@ -170,29 +423,37 @@ func buildQueryParams(
 		SelectedFacets []SelectedFacet `json:"selectedFacets"`
 	}
 
-	variables := Variables{
-		First: size,
-		After: strconv.Itoa((page - 1) * size),
-		Sort:  "score_desc",
-		Term:  "",
-		SelectedFacets: []SelectedFacet{
-			{
-				Key:   "category-1",
-				Value: category,
-			},
-			{
-				Key:   "region-id",
-				Value: "U1cjYXRhY2FkYW9icjMw",
-			},
-			{
-				Key:   "channel",
-				Value: "{\"salesChannel\":\"1\",\"seller\":\"atacadaobr30\",\"regionId\":\"U1cjYXRhY2FkYW9icjMw\"}",
-			},
-			{
-				Key:   "locale",
-				Value: "pt-BR",
-			},
+	// Each level of the category path is a facet of its own, e.g.
+	// "bebidas/cervejas" selects category-1 bebidas and category-2 cervejas.
+	selectedFacets := []SelectedFacet{}
+	for i, slug := range strings.Split(req.Category, "/") {
+		selectedFacets = append(selectedFacets, SelectedFacet{
+			Key:   fmt.Sprintf("category-%d", i+1),
+			Value: slug,
+		})
+	}
+	selectedFacets = append(
+		selectedFacets,
+		SelectedFacet{
+			Key:   "region-id",
+			Value: req.Store.RegionID,
+		},
+		SelectedFacet{
+			Key:   "channel",
+			Value: string(channel),
 		},
+		SelectedFacet{
+			Key:   "locale",
+			Value: "pt-BR",
+		},
+	)
+
+	variables := Variables{
+		First:          size,
+		After:          strconv.Itoa((page - 1) * size),
+		Sort:           "score_desc",
+		Term:           "",
+		SelectedFacets: selectedFacets,
 	}
 
 	variablesJSON, err := json.Marshal(variables)
@@ -211,18 +472,29 @@ type response struct {
 	TotalCount int64            `json:"total_count"`
 }
 
-func parseResponse(res *resty.Response) (*response, error) {
-	type Offers struct {
-		HighPrice float64 `json:"highPrice"`
-		LowPrice  float64 `json:"lowPrice"`
+func parseResponse(raw []byte) (*response, error) {
+	type Brand struct {
+		Name string `json:"name"`
+	}
+
+	type Image struct {
+		URL string `json:"url"`
+	}
+
+	type BreadcrumbList struct {
+		ItemListElement []breadcrumbItem `json:"itemListElement"`
 	}
 
 	type Node struct {
-		ID     string `json:"id"`
-		Sku    string `json:"sku"`
-		Name   string `json:"name"`
-		Gtin   string `json:"gtin"`
-		Offers Offers `json:"offers"`
+		ID             string         `json:"id"`
+		Sku            string         `json:"sku"`
+		Name           string         `json:"name"`
+		Gtin           string         `json:"gtin"`
+		Slug           string         `json:"slug"`
+		Brand          Brand          `json:"brand"`
+		Image          []Image        `json:"image"`
+		BreadcrumbList BreadcrumbList `json:"breadcrumbList"`
+		Offers         aggregateOffer `json:"offers"`
 	}
 
 	type Edge struct {
@@ -251,7 +523,7 @@ func parseResponse(res *resty.Response) (*response, error) {
 	}
 
 	var apiResponse APIResponse
-	if err := json.Unmarshal(res.Bytes(), &apiResponse); err != nil {
+	if err := json.Unmarshal(raw, &apiResponse); err != nil {
 		return nil, errs.New(err)
 	}
 
@@ -260,12 +532,33 @@ func parseResponse(res *resty.Response) (*response, error) {
 		len(apiResponse.Data.Search.Products.Edges),
 	)
 	for i, edge := range apiResponse.Data.Search.Products.Edges {
-		code := cmp.Or(edge.Node.Sku, edge.Node.Gtin)
 		products[i] = entity.Product{
-			Code:  &code,
-			Name:  edge.Node.Name,
-			Price: max(edge.Node.Offers.HighPrice, edge.Node.Offers.LowPrice),
+			RetailerID: RetailerID,
+			Name:       edge.Node.Name,
+		}
+		setPrices(&products[i], edge.Node.Offers)
+		if code := cmp.Or(edge.Node.Sku, edge.Node.Gtin); code != "" {
+			products[i].Code = &code
+		}
+		if gtin := edge.Node.Gtin; gtin != "" {
+			products[i].GTIN = &gtin
+		}
+		if brand := strings.TrimSpace(edge.Node.Brand.Name); brand != "" {
+			products[i].Brand = &brand
 		}
+		if slug := edge.Node.Slug; slug != "" {
+			products[i].Slug = &slug
+		}
+		if len(edge.Node.Image) > 0 && edge.Node.Image[0].URL != "" {
+			products[i].ImageURL = &edge.Node.Image[0].URL
+		}
+		if breadcrumb := parseBreadcrumb(
+			edge.Node.BreadcrumbList.ItemListElement,
+			edge.Node.Name,
+		); breadcrumb != "" {
+			products[i].Breadcrumb = &breadcrumb
+		}
+		setOffer(&products[i], edge.Node.Offers)
 	}
 
 	parsedResponse := &response{
@@ -276,4 +569,116 @@ func parseResponse(res *resty.Response) (*response, error) {
 	return parsedResponse, nil
 }
 
+type breadcrumbItem struct {
+	Name     string `json:"name"`
+	Position int    `json:"position"`
+}
+
+// parseBreadcrumb joins the names of the categories the product is listed
+// under, e.g. "Bebidas > Cervejas", leaving out the product itself.
+func parseBreadcrumb(items []breadcrumbItem, productName string) string {
+	items = slices.Clone(items)
+	slices.SortStableFunc(items, func(a, b breadcrumbItem) int {
+		return a.Position - b.Position
+	})
+
+	names := []string{}
+	for _, item := range items {
+		if item.Name == "" || item.Name == productName {
+			continue
+		}
+		names = append(names, item.Name)
+	}
+
+	return strings.Join(names, " > ")
+}
+
+type seller struct {
+	Identifier string `json:"identifier"`
+}
+
+type offer struct {
+	Price        float64 `json:"price"`
+	ListPrice    float64 `json:"listPrice"`
+	Quantity     int     `json:"quantity"`
+	Availability string  `json:"availability"`
+	Seller       seller  `json:"seller"`
+}
+
+// setOffer sets whether the product is in stock and who sells it. Products
+// the API returns no offers for are left unknown.
+func setOffer(product *entity.Product, offers aggregateOffer) {
+	if len(offers.Offers) == 0 {
+		return
+	}
+
+	available := false
+	for _, o := range offers.Offers {
+		if strings.HasSuffix(o.Availability, "InStock") {
+			available = true
+			break
+		}
+	}
+	product.Available = &available
+
+	if identifier := offers.Offers[0].Seller.Identifier; identifier != "" {
+		product.Seller = &identifier
+	}
+}
+
+type aggregateOffer struct {
+	HighPrice float64 `json:"highPrice"`
+	LowPrice  float64 `json:"lowPrice"`
+	Offers    []offer `json:"offers"`
+}
+
+// setPrices sets the price tiers of the product from its offers. The retail
+// offer is sold by the unit, while the wholesale one ("a partir de X
+// unidades") requires a minimum quantity and is cheaper. The price of the
+// product is what a single unit costs.
+func setPrices(product *entity.Product, offers aggregateOffer) {
+	var retail *offer
+	var wholesale *offer
+	for i, o := range offers.Offers {
+		if o.Price <= 0 {
+			continue
+		}
+		if o.Quantity <= 1 {
+			if retail == nil {
+				retail = &offers.Offers[i]
+			}
+			continue
+		}
+		if wholesale == nil || o.Price < wholesale.Price {
+			wholesale = &offers.Offers[i]
+		}
+	}
+
+	price, listPrice := offers.HighPrice, offers.HighPrice
+	if retail != nil {
+		price, listPrice = retail.Price, max(retail.ListPrice, retail.Price)
+	}
+	if price <= 0 {
+		price, listPrice = offers.LowPrice, offers.LowPrice
+	}
+
+	product.Price = price
+	if listPrice > 0 {
+		product.ListPrice = &listPrice
+	}
+	if price < listPrice {
+		product.PromoPrice = &price
+	}
+
+	switch {
+	case wholesale != nil && wholesale.Price < price:
+		product.WholesalePrice = &wholesale.Price
+		product.WholesaleMinQuantity = &wholesale.Quantity
+	case offers.LowPrice > 0 && offers.LowPrice < price:
+		// The lowest price is a wholesale one whose minimum quantity the
+		// API did not tell.
+		product.WholesalePrice = &offers.LowPrice
+	}
+}
+
 var _ supermarketapi.SupermarketAPI = (*AtacadaoAPI)(nil)
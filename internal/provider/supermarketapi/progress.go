@@ -0,0 +1,36 @@
+package supermarketapi
+
+import "github.com/danielmesquitta/supermarket-scraper/internal/domain/entity"
+
+// Progress tells which pages of each category were already processed.
+type Progress struct {
+	pages      map[string]map[int]bool
+	totalPages map[string]int
+}
+
+func NewProgress(checkpoints []entity.ScrapeCheckpoint) *Progress {
+	p := &Progress{
+		pages:      map[string]map[int]bool{},
+		totalPages: map[string]int{},
+	}
+
+	for _, checkpoint := range checkpoints {
+		if _, ok := p.pages[checkpoint.Category]; !ok {
+			p.pages[checkpoint.Category] = map[int]bool{}
+		}
+		p.pages[checkpoint.Category][checkpoint.Page] = true
+		p.totalPages[checkpoint.Category] = checkpoint.TotalPages
+	}
+
+	return p
+}
+
+func (p *Progress) Done(category string, page int) bool {
+	return p.pages[category][page]
+}
+
+// TotalPages returns the number of pages of the category, or 0 when no
+// page of it was processed yet.
+func (p *Progress) TotalPages(category string) int {
+	return p.totalPages[category]
+}
@@ -0,0 +1,74 @@
+package supermarketapi
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/danielmesquitta/supermarket-scraper/internal/domain/errs"
+)
+
+// Registry holds every SupermarketAPI implementation by retailer ID.
+type Registry struct {
+	apis map[string]SupermarketAPI
+	ids  []string
+}
+
+func NewRegistry(apis ...SupermarketAPI) *Registry {
+	r := &Registry{
+		apis: map[string]SupermarketAPI{},
+	}
+
+	for _, api := range apis {
+		id := api.Retailer().ID
+		r.apis[id] = api
+		r.ids = append(r.ids, id)
+	}
+
+	slices.Sort(r.ids)
+
+	return r
+}
+
+// IDs returns the registered retailer IDs, sorted.
+func (r *Registry) IDs() []string {
+	return slices.Clone(r.ids)
+}
+
+// Get returns the implementation registered for the retailer ID.
+func (r *Registry) Get(id string) (SupermarketAPI, error) {
+	api, ok := r.apis[id]
+	if !ok {
+		return nil, errs.New(
+			fmt.Sprintf(
+				"unknown retailer %q, available retailers: %s",
+				id,
+				strings.Join(r.ids, ", "),
+			),
+		)
+	}
+
+	return api, nil
+}
+
+// Select returns the implementations registered for the retailer IDs,
+// or all of them when no ID is given.
+func (r *Registry) Select(ids []string) ([]SupermarketAPI, error) {
+	if len(ids) == 0 {
+		ids = r.ids
+	}
+
+	apis := make([]SupermarketAPI, 0, len(ids))
+	for _, id := range ids {
+		api, err := r.Get(id)
+		if err != nil {
+			return nil, errs.New(err)
+		}
+		if slices.Contains(apis, api) {
+			continue
+		}
+		apis = append(apis, api)
+	}
+
+	return apis, nil
+}
@@ -7,5 +7,63 @@ import (
 )
 
 type SupermarketAPI interface {
-	ListProducts(ctx context.Context) ([]entity.Product, error)
+	Retailer() entity.Retailer
+	// ListStores resolves the stores configured to be crawled, each of which
+	// may have its own prices.
+	ListStores(ctx context.Context) ([]entity.Store, error)
+	// ListCategories discovers the category tree the retailer currently
+	// offers.
+	ListCategories(ctx context.Context) ([]Category, error)
+	// ListProducts crawls every product of the retailer, handing each page
+	// to opts.OnPage as soon as it is fetched.
+	ListProducts(ctx context.Context, opts ListProductsOptions) error
+	// ListProductsPage fetches a single page of products, which is how
+	// failed pages are retried.
+	ListProductsPage(ctx context.Context, req PageRequest) (*Page, error)
+	// ParsePage parses the raw body of a page fetched earlier, so products
+	// can be rebuilt from stored payloads without requesting them again.
+	ParsePage(req PageRequest, raw []byte) (*Page, error)
+}
+
+type Category struct {
+	Name     string
+	Slug     string
+	Children []Category
+}
+
+type PageRequest struct {
+	Store entity.Store
+	// Category is the path of the category, its slug prefixed by the slugs
+	// of its ancestors, e.g. "bebidas/cervejas".
+	Category string
+	Page     int
+	Size     int
+}
+
+type Page struct {
+	PageRequest
+	TotalPages int
+	Products   []entity.Product
+	// Raw is the body of the response the page was parsed from.
+	Raw []byte
+}
+
+type ListProductsOptions struct {
+	// Store is the store whose products and prices are crawled.
+	Store entity.Store
+	// Categories lists the paths of the categories to crawl.
+	Categories []string
+	// PageSize is the number of products per page, the one the retailer is
+	// configured with when 0.
+	PageSize int
+	// Checkpoints lists the pages already processed by a previous attempt
+	// of the run, which are not requested again. They count pages of
+	// PageSize products.
+	Checkpoints []entity.ScrapeCheckpoint
+	// OnPage is called for every fetched page. Returning an error aborts
+	// the crawl.
+	OnPage func(ctx context.Context, page Page) error
+	// OnPageError is called for every page that could not be fetched.
+	// The crawl carries on with the other pages.
+	OnPageError func(ctx context.Context, req PageRequest, err error)
 }
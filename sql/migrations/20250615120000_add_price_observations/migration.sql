@@ -0,0 +1,20 @@
+-- CreateTable
+CREATE TABLE "price_observations" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "price" REAL NOT NULL,
+    "source" TEXT NOT NULL,
+    "run_id" TEXT,
+    "observed_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "price_observations_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+-- CreateIndex
+CREATE INDEX "price_observations_product_id_observed_at_idx" ON "price_observations"("product_id", "observed_at");
+
+-- CreateIndex
+CREATE INDEX "price_observations_run_id_idx" ON "price_observations"("run_id");
+
+-- Backfill the first known price of every existing product
+INSERT INTO "price_observations" ("id", "product_id", "price", "source", "observed_at")
+SELECT "id", "id", "price", 'backfill', "created_at" FROM "products";
@@ -0,0 +1,41 @@
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN "retailer_id" TEXT NOT NULL DEFAULT 'atacadao';
+
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN "normalized_name" TEXT NOT NULL DEFAULT '';
+
+-- Backfill normalized names, folding the diacritics used in Portuguese
+UPDATE "products" SET "normalized_name" = replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(replace(lower(trim("name")), 'á', 'a'), 'à', 'a'), 'â', 'a'), 'ã', 'a'), 'ä', 'a'), 'é', 'e'), 'è', 'e'), 'ê', 'e'), 'ë', 'e'), 'í', 'i'), 'ì', 'i'), 'î', 'i'), 'ï', 'i'), 'ó', 'o'), 'ò', 'o'), 'ô', 'o'), 'õ', 'o'), 'ö', 'o'), 'ú', 'u'), 'ù', 'u'), 'û', 'u'), 'ü', 'u'), 'ç', 'c'), 'ñ', 'n'), 'Á', 'a'), 'À', 'a'), 'Â', 'a'), 'Ã', 'a'), 'Ä', 'a'), 'É', 'e'), 'È', 'e'), 'Ê', 'e'), 'Ë', 'e'), 'Í', 'i'), 'Ì', 'i'), 'Î', 'i'), 'Ï', 'i'), 'Ó', 'o'), 'Ò', 'o'), 'Ô', 'o'), 'Õ', 'o'), 'Ö', 'o'), 'Ú', 'u'), 'Ù', 'u'), 'Û', 'u'), 'Ü', 'u'), 'Ç', 'c'), 'Ñ', 'n');
+
+-- Products without a retailer code fall back to the normalized name
+UPDATE "products" SET "code" = NULL WHERE "code" = '';
+
+-- Merge listings that share a retailer code into the oldest one
+CREATE TEMP TABLE "product_merges" AS
+SELECT p."id" AS "id", (
+    SELECT k."id" FROM "products" k
+    WHERE k."retailer_id" = p."retailer_id" AND k."code" = p."code"
+    ORDER BY k."created_at", k."id"
+    LIMIT 1
+) AS "keep_id"
+FROM "products" p
+WHERE p."code" IS NOT NULL;
+
+DELETE FROM "product_merges" WHERE "id" = "keep_id";
+
+UPDATE "price_observations"
+SET "product_id" = (
+    SELECT m."keep_id" FROM "product_merges" m
+    WHERE m."id" = "price_observations"."product_id"
+)
+WHERE "product_id" IN (SELECT "id" FROM "product_merges");
+
+DELETE FROM "products" WHERE "id" IN (SELECT "id" FROM "product_merges");
+
+DROP TABLE "product_merges";
+
+-- CreateIndex
+CREATE UNIQUE INDEX "products_retailer_id_code_key" ON "products"("retailer_id", "code");
+
+-- CreateIndex
+CREATE INDEX "products_retailer_id_normalized_name_idx" ON "products"("retailer_id", "normalized_name");
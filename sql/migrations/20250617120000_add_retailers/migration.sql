@@ -0,0 +1,31 @@
+-- CreateTable
+CREATE TABLE "retailers" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "name" TEXT NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Seed the retailer every existing product came from
+INSERT INTO "retailers" ("id", "name") VALUES ('atacadao', 'Atacadão');
+
+-- RedefineTables
+PRAGMA defer_foreign_keys=ON;
+PRAGMA foreign_keys=OFF;
+CREATE TABLE "new_products" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "retailer_id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "normalized_name" TEXT NOT NULL DEFAULT '',
+    "price" REAL NOT NULL,
+    "code" TEXT,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" DATETIME,
+    CONSTRAINT "products_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers" ("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+INSERT INTO "new_products" ("code", "created_at", "deleted_at", "id", "name", "normalized_name", "price", "retailer_id") SELECT "code", "created_at", "deleted_at", "id", "name", "normalized_name", "price", "retailer_id" FROM "products";
+DROP TABLE "products";
+ALTER TABLE "new_products" RENAME TO "products";
+CREATE INDEX "products_retailer_id_normalized_name_idx" ON "products"("retailer_id", "normalized_name");
+CREATE UNIQUE INDEX "products_retailer_id_code_key" ON "products"("retailer_id", "code");
+PRAGMA foreign_keys=ON;
+PRAGMA defer_foreign_keys=OFF;
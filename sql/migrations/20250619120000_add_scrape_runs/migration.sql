@@ -0,0 +1,61 @@
+-- CreateTable
+CREATE TABLE "scrape_runs" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "retailer_id" TEXT NOT NULL,
+    "source" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "product_count" INTEGER NOT NULL DEFAULT 0,
+    "error_count" INTEGER NOT NULL DEFAULT 0,
+    "category_counts" TEXT NOT NULL DEFAULT '{}',
+    "started_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "finished_at" DATETIME,
+    CONSTRAINT "scrape_runs_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers" ("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+-- Backfill the runs already referenced by price observations,
+-- whose outcome was never recorded
+INSERT INTO "scrape_runs" ("id", "retailer_id", "source", "status", "product_count", "started_at", "finished_at")
+SELECT o."run_id", MIN(p."retailer_id"), MIN(o."source"), 'unknown', COUNT(*), MIN(o."observed_at"), MAX(o."observed_at")
+FROM "price_observations" o
+JOIN "products" p ON p."id" = o."product_id"
+WHERE o."run_id" IS NOT NULL
+GROUP BY o."run_id";
+
+-- RedefineTables
+PRAGMA defer_foreign_keys=ON;
+PRAGMA foreign_keys=OFF;
+CREATE TABLE "new_price_observations" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "price" REAL NOT NULL,
+    "source" TEXT NOT NULL,
+    "run_id" TEXT,
+    "observed_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "price_observations_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "price_observations_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+INSERT INTO "new_price_observations" ("id", "observed_at", "price", "product_id", "run_id", "source") SELECT "id", "observed_at", "price", "product_id", "run_id", "source" FROM "price_observations";
+DROP TABLE "price_observations";
+ALTER TABLE "new_price_observations" RENAME TO "price_observations";
+CREATE INDEX "price_observations_product_id_observed_at_idx" ON "price_observations"("product_id", "observed_at");
+CREATE INDEX "price_observations_run_id_idx" ON "price_observations"("run_id");
+CREATE TABLE "new_errors" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "run_id" TEXT,
+    "message" TEXT NOT NULL,
+    "type" TEXT NOT NULL,
+    "stack_trace" TEXT NOT NULL,
+    "metadata" TEXT NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" DATETIME,
+    CONSTRAINT "errors_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+INSERT INTO "new_errors" ("created_at", "deleted_at", "id", "message", "metadata", "stack_trace", "type") SELECT "created_at", "deleted_at", "id", "message", "metadata", "stack_trace", "type" FROM "errors";
+DROP TABLE "errors";
+ALTER TABLE "new_errors" RENAME TO "errors";
+CREATE INDEX "errors_run_id_idx" ON "errors"("run_id");
+PRAGMA foreign_keys=ON;
+PRAGMA defer_foreign_keys=OFF;
+
+-- CreateIndex
+CREATE INDEX "scrape_runs_retailer_id_started_at_idx" ON "scrape_runs"("retailer_id", "started_at");
@@ -0,0 +1,14 @@
+-- CreateTable
+CREATE TABLE "scrape_checkpoints" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "run_id" TEXT NOT NULL,
+    "category" TEXT NOT NULL,
+    "page" INTEGER NOT NULL,
+    "total_pages" INTEGER NOT NULL,
+    "product_count" INTEGER NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "scrape_checkpoints_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "scrape_checkpoints_run_id_category_page_key" ON "scrape_checkpoints"("run_id", "category", "page");
@@ -0,0 +1,5 @@
+-- AlterTable
+ALTER TABLE "errors" ADD COLUMN "attempts" INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE "errors" ADD COLUMN "last_attempt_at" DATETIME;
+ALTER TABLE "errors" ADD COLUMN "last_failure" TEXT;
+ALTER TABLE "errors" ADD COLUMN "permanently_failed_at" DATETIME;
@@ -0,0 +1,46 @@
+-- CreateTable
+CREATE TABLE "categories" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "retailer_id" TEXT NOT NULL,
+    "parent_id" TEXT,
+    "name" TEXT NOT NULL,
+    "slug" TEXT NOT NULL,
+    "path" TEXT NOT NULL,
+    "level" INTEGER NOT NULL,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" DATETIME,
+    CONSTRAINT "categories_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers" ("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "categories_parent_id_fkey" FOREIGN KEY ("parent_id") REFERENCES "categories" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+
+-- RedefineTables
+PRAGMA defer_foreign_keys=ON;
+PRAGMA foreign_keys=OFF;
+CREATE TABLE "new_products" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "retailer_id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "normalized_name" TEXT NOT NULL DEFAULT '',
+    "price" REAL NOT NULL,
+    "code" TEXT,
+    "category_id" TEXT,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" DATETIME,
+    CONSTRAINT "products_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers" ("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "products_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+INSERT INTO "new_products" ("code", "created_at", "deleted_at", "id", "name", "normalized_name", "price", "retailer_id") SELECT "code", "created_at", "deleted_at", "id", "name", "normalized_name", "price", "retailer_id" FROM "products";
+DROP TABLE "products";
+ALTER TABLE "new_products" RENAME TO "products";
+CREATE INDEX "products_retailer_id_normalized_name_idx" ON "products"("retailer_id", "normalized_name");
+CREATE INDEX "products_category_id_idx" ON "products"("category_id");
+CREATE UNIQUE INDEX "products_retailer_id_code_key" ON "products"("retailer_id", "code");
+PRAGMA foreign_keys=ON;
+PRAGMA defer_foreign_keys=OFF;
+
+-- CreateIndex
+CREATE INDEX "categories_parent_id_idx" ON "categories"("parent_id");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "categories_retailer_id_path_key" ON "categories"("retailer_id", "path");
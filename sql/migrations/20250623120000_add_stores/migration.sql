@@ -0,0 +1,67 @@
+-- CreateTable
+CREATE TABLE "stores" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "retailer_id" TEXT NOT NULL,
+    "code" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "region_id" TEXT NOT NULL,
+    "postal_code" TEXT,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "stores_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers" ("id") ON DELETE RESTRICT ON UPDATE CASCADE
+);
+
+-- Seed the only store the API scraper used to crawl
+INSERT INTO "stores" ("id", "retailer_id", "code", "name", "region_id")
+VALUES (lower(hex(randomblob(16))), 'atacadao', 'atacadaobr30', 'atacadaobr30', 'U1cjYXRhY2FkYW9icjMw');
+
+-- RedefineTables
+PRAGMA defer_foreign_keys=ON;
+PRAGMA foreign_keys=OFF;
+CREATE TABLE "new_price_observations" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "price" REAL NOT NULL,
+    "source" TEXT NOT NULL,
+    "run_id" TEXT,
+    "store_id" TEXT,
+    "observed_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "price_observations_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "price_observations_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs" ("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "price_observations_store_id_fkey" FOREIGN KEY ("store_id") REFERENCES "stores" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+INSERT INTO "new_price_observations" ("id", "observed_at", "price", "product_id", "run_id", "source") SELECT "id", "observed_at", "price", "product_id", "run_id", "source" FROM "price_observations";
+DROP TABLE "price_observations";
+ALTER TABLE "new_price_observations" RENAME TO "price_observations";
+CREATE INDEX "price_observations_product_id_observed_at_idx" ON "price_observations"("product_id", "observed_at");
+CREATE INDEX "price_observations_run_id_idx" ON "price_observations"("run_id");
+CREATE INDEX "price_observations_store_id_idx" ON "price_observations"("store_id");
+CREATE TABLE "new_scrape_runs" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "retailer_id" TEXT NOT NULL,
+    "store_id" TEXT,
+    "source" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "product_count" INTEGER NOT NULL DEFAULT 0,
+    "error_count" INTEGER NOT NULL DEFAULT 0,
+    "category_counts" TEXT NOT NULL DEFAULT '{}',
+    "started_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "finished_at" DATETIME,
+    CONSTRAINT "scrape_runs_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers" ("id") ON DELETE RESTRICT ON UPDATE CASCADE,
+    CONSTRAINT "scrape_runs_store_id_fkey" FOREIGN KEY ("store_id") REFERENCES "stores" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
+INSERT INTO "new_scrape_runs" ("category_counts", "error_count", "finished_at", "id", "product_count", "retailer_id", "source", "started_at", "status") SELECT "category_counts", "error_count", "finished_at", "id", "product_count", "retailer_id", "source", "started_at", "status" FROM "scrape_runs";
+DROP TABLE "scrape_runs";
+ALTER TABLE "new_scrape_runs" RENAME TO "scrape_runs";
+CREATE INDEX "scrape_runs_retailer_id_started_at_idx" ON "scrape_runs"("retailer_id", "started_at");
+PRAGMA foreign_keys=ON;
+PRAGMA defer_foreign_keys=OFF;
+
+-- Every API run so far crawled the seeded store
+UPDATE "scrape_runs" SET "store_id" = (SELECT "id" FROM "stores" WHERE "code" = 'atacadaobr30')
+WHERE "source" = 'api';
+UPDATE "price_observations" SET "store_id" = (SELECT "id" FROM "stores" WHERE "code" = 'atacadaobr30')
+WHERE "source" = 'api';
+
+-- CreateIndex
+CREATE UNIQUE INDEX "stores_retailer_id_code_key" ON "stores"("retailer_id", "code");
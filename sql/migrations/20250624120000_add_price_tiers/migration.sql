@@ -0,0 +1,11 @@
+-- AlterTable
+ALTER TABLE "price_observations" ADD COLUMN "list_price" REAL;
+ALTER TABLE "price_observations" ADD COLUMN "promo_price" REAL;
+ALTER TABLE "price_observations" ADD COLUMN "wholesale_min_quantity" INTEGER;
+ALTER TABLE "price_observations" ADD COLUMN "wholesale_price" REAL;
+
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN "list_price" REAL;
+ALTER TABLE "products" ADD COLUMN "promo_price" REAL;
+ALTER TABLE "products" ADD COLUMN "wholesale_min_quantity" INTEGER;
+ALTER TABLE "products" ADD COLUMN "wholesale_price" REAL;
@@ -0,0 +1,9 @@
+-- AlterTable
+ALTER TABLE "price_observations" ADD COLUMN "unit_price" REAL;
+ALTER TABLE "price_observations" ADD COLUMN "unit_price_unit" TEXT;
+
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN "brand" TEXT;
+ALTER TABLE "products" ADD COLUMN "pack_count" INTEGER;
+ALTER TABLE "products" ADD COLUMN "quantity" REAL;
+ALTER TABLE "products" ADD COLUMN "unit" TEXT;
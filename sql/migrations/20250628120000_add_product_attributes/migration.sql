@@ -0,0 +1,7 @@
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN "available" BOOLEAN;
+ALTER TABLE "products" ADD COLUMN "breadcrumb" TEXT;
+ALTER TABLE "products" ADD COLUMN "image_url" TEXT;
+ALTER TABLE "products" ADD COLUMN "seller" TEXT;
+ALTER TABLE "products" ADD COLUMN "slug" TEXT;
+ALTER TABLE "products" ADD COLUMN "url" TEXT;
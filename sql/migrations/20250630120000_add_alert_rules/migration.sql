@@ -0,0 +1,16 @@
+-- CreateTable
+CREATE TABLE "alert_rules" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "name" TEXT NOT NULL,
+    "event" TEXT,
+    "retailer_id" TEXT,
+    "product_id" TEXT,
+    "category_id" TEXT,
+    "threshold_percent" REAL NOT NULL DEFAULT 0,
+    "created_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" DATETIME,
+    CONSTRAINT "alert_rules_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers" ("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "alert_rules_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE SET NULL ON UPDATE CASCADE,
+    CONSTRAINT "alert_rules_category_id_fkey" FOREIGN KEY ("category_id") REFERENCES "categories" ("id") ON DELETE SET NULL ON UPDATE CASCADE
+);
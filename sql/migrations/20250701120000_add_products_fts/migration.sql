@@ -0,0 +1,108 @@
+-- Full-text index over the names, brands and categories of the products,
+-- matching words regardless of their accents. Requires SQLite built with
+-- FTS5.
+--
+-- The index is keyed by product_search_ids, which gives every product a
+-- stable integer, as the rowids of products may change on VACUUM.
+
+-- CreateTable
+CREATE TABLE "product_search_ids" (
+    "id" INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+    "product_id" TEXT NOT NULL,
+    CONSTRAINT "product_search_ids_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "product_search_ids_product_id_key" ON "product_search_ids"("product_id");
+
+-- CreateVirtualTable
+CREATE VIRTUAL TABLE "products_fts" USING fts5(
+    "name",
+    "brand",
+    "category",
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+-- Backfill
+INSERT INTO "product_search_ids" ("product_id") SELECT "id" FROM "products";
+
+INSERT INTO "products_fts" ("rowid", "name", "brand", "category")
+SELECT
+    "s"."id",
+    "p"."name",
+    COALESCE("p"."brand", ''),
+    COALESCE("c"."name", '') || ' ' || COALESCE("p"."breadcrumb", '')
+FROM "product_search_ids" "s"
+JOIN "products" "p" ON "p"."id" = "s"."product_id"
+LEFT JOIN "categories" "c" ON "c"."id" = "p"."category_id";
+
+-- CreateTrigger
+CREATE TRIGGER "products_fts_insert" AFTER INSERT ON "products"
+BEGIN
+    INSERT INTO "product_search_ids" ("product_id") VALUES (NEW."id");
+
+    INSERT INTO "products_fts" ("rowid", "name", "brand", "category")
+    SELECT
+        "s"."id",
+        NEW."name",
+        COALESCE(NEW."brand", ''),
+        COALESCE(
+            (SELECT "name" FROM "categories" WHERE "id" = NEW."category_id"),
+            ''
+        ) || ' ' || COALESCE(NEW."breadcrumb", '')
+    FROM "product_search_ids" "s"
+    WHERE "s"."product_id" = NEW."id";
+END;
+
+-- CreateTrigger
+CREATE TRIGGER "products_fts_update"
+AFTER UPDATE OF "name", "brand", "category_id", "breadcrumb" ON "products"
+BEGIN
+    DELETE FROM "products_fts"
+    WHERE "rowid" = (
+        SELECT "id" FROM "product_search_ids" WHERE "product_id" = OLD."id"
+    );
+
+    INSERT INTO "products_fts" ("rowid", "name", "brand", "category")
+    SELECT
+        "s"."id",
+        NEW."name",
+        COALESCE(NEW."brand", ''),
+        COALESCE(
+            (SELECT "name" FROM "categories" WHERE "id" = NEW."category_id"),
+            ''
+        ) || ' ' || COALESCE(NEW."breadcrumb", '')
+    FROM "product_search_ids" "s"
+    WHERE "s"."product_id" = NEW."id";
+END;
+
+-- CreateTrigger
+CREATE TRIGGER "products_fts_delete" AFTER DELETE ON "products"
+BEGIN
+    DELETE FROM "products_fts"
+    WHERE "rowid" = (
+        SELECT "id" FROM "product_search_ids" WHERE "product_id" = OLD."id"
+    );
+
+    DELETE FROM "product_search_ids" WHERE "product_id" = OLD."id";
+END;
+
+-- CreateTrigger
+CREATE TRIGGER "products_fts_category_update"
+AFTER UPDATE OF "name" ON "categories"
+WHEN OLD."name" <> NEW."name"
+BEGIN
+    UPDATE "products_fts"
+    SET "category" = (
+        SELECT NEW."name" || ' ' || COALESCE("p"."breadcrumb", '')
+        FROM "product_search_ids" "s"
+        JOIN "products" "p" ON "p"."id" = "s"."product_id"
+        WHERE "s"."id" = "products_fts"."rowid"
+    )
+    WHERE "rowid" IN (
+        SELECT "s"."id"
+        FROM "product_search_ids" "s"
+        JOIN "products" "p" ON "p"."id" = "s"."product_id"
+        WHERE "p"."category_id" = NEW."id"
+    );
+END;
@@ -0,0 +1,2 @@
+-- AlterTable
+ALTER TABLE "price_observations" ADD COLUMN "available" BOOLEAN;
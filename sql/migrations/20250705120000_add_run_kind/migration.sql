@@ -0,0 +1,2 @@
+-- AlterTable
+ALTER TABLE "scrape_runs" ADD COLUMN "kind" TEXT NOT NULL DEFAULT 'crawl';
@@ -0,0 +1,45 @@
+-- CreateTable
+CREATE TABLE "product_prices" (
+    "id" TEXT NOT NULL PRIMARY KEY,
+    "product_id" TEXT NOT NULL,
+    "store_id" TEXT,
+    "price" REAL NOT NULL,
+    "list_price" REAL,
+    "promo_price" REAL,
+    "wholesale_price" REAL,
+    "wholesale_min_quantity" INTEGER,
+    "unit_price" REAL,
+    "unit_price_unit" TEXT,
+    "available" BOOLEAN,
+    "observed_at" DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT "product_prices_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products" ("id") ON DELETE CASCADE ON UPDATE CASCADE,
+    CONSTRAINT "product_prices_store_id_fkey" FOREIGN KEY ("store_id") REFERENCES "stores" ("id") ON DELETE CASCADE ON UPDATE CASCADE
+);
+
+-- CreateIndex
+CREATE INDEX "product_prices_product_id_idx" ON "product_prices"("product_id");
+
+-- CreateIndex
+CREATE INDEX "product_prices_store_id_price_idx" ON "product_prices"("store_id", "price");
+
+-- Backfill the latest observation of each product at each store
+INSERT INTO "product_prices" (
+    "id", "product_id", "store_id", "price", "list_price", "promo_price",
+    "wholesale_price", "wholesale_min_quantity", "unit_price",
+    "unit_price_unit", "available", "observed_at"
+)
+SELECT
+    "product_id" || ':' || COALESCE("store_id", ''), "product_id", "store_id",
+    "price", "list_price", "promo_price", "wholesale_price",
+    "wholesale_min_quantity", "unit_price", "unit_price_unit", "available",
+    "observed_at"
+FROM (
+    SELECT
+        *,
+        ROW_NUMBER() OVER (
+            PARTITION BY "product_id", "store_id"
+            ORDER BY "observed_at" DESC, "id" DESC
+        ) AS "position"
+    FROM "price_observations"
+) AS "latest"
+WHERE "position" = 1;
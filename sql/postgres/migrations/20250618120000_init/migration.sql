@@ -0,0 +1,65 @@
+-- CreateTable
+CREATE TABLE "retailers" (
+    "id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "retailers_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "products" (
+    "id" TEXT NOT NULL,
+    "retailer_id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "normalized_name" TEXT NOT NULL DEFAULT '',
+    "price" DOUBLE PRECISION NOT NULL,
+    "code" TEXT,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMP(3),
+
+    CONSTRAINT "products_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "price_observations" (
+    "id" TEXT NOT NULL,
+    "product_id" TEXT NOT NULL,
+    "price" DOUBLE PRECISION NOT NULL,
+    "source" TEXT NOT NULL,
+    "run_id" TEXT,
+    "observed_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "price_observations_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "errors" (
+    "id" TEXT NOT NULL,
+    "message" TEXT NOT NULL,
+    "type" TEXT NOT NULL,
+    "stack_trace" TEXT NOT NULL,
+    "metadata" TEXT NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "deleted_at" TIMESTAMP(3),
+
+    CONSTRAINT "errors_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "products_retailer_id_normalized_name_idx" ON "products"("retailer_id", "normalized_name");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "products_retailer_id_code_key" ON "products"("retailer_id", "code");
+
+-- CreateIndex
+CREATE INDEX "price_observations_product_id_observed_at_idx" ON "price_observations"("product_id", "observed_at");
+
+-- CreateIndex
+CREATE INDEX "price_observations_run_id_idx" ON "price_observations"("run_id");
+
+-- AddForeignKey
+ALTER TABLE "products" ADD CONSTRAINT "products_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "price_observations" ADD CONSTRAINT "price_observations_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
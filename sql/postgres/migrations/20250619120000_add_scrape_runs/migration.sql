@@ -0,0 +1,41 @@
+-- AlterTable
+ALTER TABLE "errors" ADD COLUMN "run_id" TEXT;
+
+-- CreateTable
+CREATE TABLE "scrape_runs" (
+    "id" TEXT NOT NULL,
+    "retailer_id" TEXT NOT NULL,
+    "source" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "product_count" INTEGER NOT NULL DEFAULT 0,
+    "error_count" INTEGER NOT NULL DEFAULT 0,
+    "category_counts" TEXT NOT NULL DEFAULT '{}',
+    "started_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "finished_at" TIMESTAMP(3),
+
+    CONSTRAINT "scrape_runs_pkey" PRIMARY KEY ("id")
+);
+
+-- Backfill the runs already referenced by price observations,
+-- whose outcome was never recorded
+INSERT INTO "scrape_runs" ("id", "retailer_id", "source", "status", "product_count", "started_at", "finished_at")
+SELECT o."run_id", MIN(p."retailer_id"), MIN(o."source"), 'unknown', COUNT(*), MIN(o."observed_at"), MAX(o."observed_at")
+FROM "price_observations" o
+JOIN "products" p ON p."id" = o."product_id"
+WHERE o."run_id" IS NOT NULL
+GROUP BY o."run_id";
+
+-- CreateIndex
+CREATE INDEX "scrape_runs_retailer_id_started_at_idx" ON "scrape_runs"("retailer_id", "started_at");
+
+-- CreateIndex
+CREATE INDEX "errors_run_id_idx" ON "errors"("run_id");
+
+-- AddForeignKey
+ALTER TABLE "price_observations" ADD CONSTRAINT "price_observations_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "scrape_runs" ADD CONSTRAINT "scrape_runs_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "errors" ADD CONSTRAINT "errors_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs"("id") ON DELETE SET NULL ON UPDATE CASCADE;
@@ -0,0 +1,18 @@
+-- CreateTable
+CREATE TABLE "scrape_checkpoints" (
+    "id" TEXT NOT NULL,
+    "run_id" TEXT NOT NULL,
+    "category" TEXT NOT NULL,
+    "page" INTEGER NOT NULL,
+    "total_pages" INTEGER NOT NULL,
+    "product_count" INTEGER NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "scrape_checkpoints_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "scrape_checkpoints_run_id_category_page_key" ON "scrape_checkpoints"("run_id", "category", "page");
+
+-- AddForeignKey
+ALTER TABLE "scrape_checkpoints" ADD CONSTRAINT "scrape_checkpoints_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs"("id") ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,5 @@
+-- AlterTable
+ALTER TABLE "errors" ADD COLUMN     "attempts" INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN     "last_attempt_at" TIMESTAMP(3),
+ADD COLUMN     "last_failure" TEXT,
+ADD COLUMN     "permanently_failed_at" TIMESTAMP(3);
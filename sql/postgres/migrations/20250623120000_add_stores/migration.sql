@@ -0,0 +1,34 @@
+-- AlterTable
+ALTER TABLE "price_observations" ADD COLUMN     "store_id" TEXT;
+
+-- AlterTable
+ALTER TABLE "scrape_runs" ADD COLUMN     "store_id" TEXT;
+
+-- CreateTable
+CREATE TABLE "stores" (
+    "id" TEXT NOT NULL,
+    "retailer_id" TEXT NOT NULL,
+    "code" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "region_id" TEXT NOT NULL,
+    "postal_code" TEXT,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "stores_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "stores_retailer_id_code_key" ON "stores"("retailer_id", "code");
+
+-- CreateIndex
+CREATE INDEX "price_observations_store_id_idx" ON "price_observations"("store_id");
+
+-- AddForeignKey
+ALTER TABLE "stores" ADD CONSTRAINT "stores_retailer_id_fkey" FOREIGN KEY ("retailer_id") REFERENCES "retailers"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "price_observations" ADD CONSTRAINT "price_observations_store_id_fkey" FOREIGN KEY ("store_id") REFERENCES "stores"("id") ON DELETE SET NULL ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "scrape_runs" ADD CONSTRAINT "scrape_runs_store_id_fkey" FOREIGN KEY ("store_id") REFERENCES "stores"("id") ON DELETE SET NULL ON UPDATE CASCADE;
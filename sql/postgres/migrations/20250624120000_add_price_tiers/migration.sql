@@ -0,0 +1,11 @@
+-- AlterTable
+ALTER TABLE "price_observations" ADD COLUMN     "list_price" DOUBLE PRECISION,
+ADD COLUMN     "promo_price" DOUBLE PRECISION,
+ADD COLUMN     "wholesale_min_quantity" INTEGER,
+ADD COLUMN     "wholesale_price" DOUBLE PRECISION;
+
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN     "list_price" DOUBLE PRECISION,
+ADD COLUMN     "promo_price" DOUBLE PRECISION,
+ADD COLUMN     "wholesale_min_quantity" INTEGER,
+ADD COLUMN     "wholesale_price" DOUBLE PRECISION;
@@ -0,0 +1,9 @@
+-- AlterTable
+ALTER TABLE "price_observations" ADD COLUMN     "unit_price" DOUBLE PRECISION,
+ADD COLUMN     "unit_price_unit" TEXT;
+
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN     "brand" TEXT,
+ADD COLUMN     "pack_count" INTEGER,
+ADD COLUMN     "quantity" DOUBLE PRECISION,
+ADD COLUMN     "unit" TEXT;
@@ -0,0 +1,50 @@
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN     "gtin" TEXT;
+
+-- CreateTable
+CREATE TABLE "canonical_products" (
+    "id" TEXT NOT NULL,
+    "name" TEXT NOT NULL,
+    "normalized_name" TEXT NOT NULL,
+    "brand" TEXT,
+    "quantity" DOUBLE PRECISION,
+    "unit" TEXT,
+    "pack_count" INTEGER,
+    "gtin" TEXT,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "canonical_products_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateTable
+CREATE TABLE "product_matches" (
+    "id" TEXT NOT NULL,
+    "product_id" TEXT NOT NULL,
+    "canonical_product_id" TEXT NOT NULL,
+    "retailer_id" TEXT NOT NULL,
+    "method" TEXT NOT NULL,
+    "status" TEXT NOT NULL,
+    "confidence" DOUBLE PRECISION NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    "updated_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "product_matches_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "canonical_products_gtin_key" ON "canonical_products"("gtin");
+
+-- CreateIndex
+CREATE UNIQUE INDEX "product_matches_product_id_key" ON "product_matches"("product_id");
+
+-- CreateIndex
+CREATE INDEX "product_matches_canonical_product_id_idx" ON "product_matches"("canonical_product_id");
+
+-- CreateIndex
+CREATE INDEX "product_matches_status_idx" ON "product_matches"("status");
+
+-- AddForeignKey
+ALTER TABLE "product_matches" ADD CONSTRAINT "product_matches_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "product_matches" ADD CONSTRAINT "product_matches_canonical_product_id_fkey" FOREIGN KEY ("canonical_product_id") REFERENCES "canonical_products"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
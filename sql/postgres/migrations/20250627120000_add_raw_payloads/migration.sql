@@ -0,0 +1,18 @@
+-- CreateTable
+CREATE TABLE "raw_payloads" (
+    "id" TEXT NOT NULL,
+    "run_id" TEXT NOT NULL,
+    "category" TEXT NOT NULL,
+    "page" INTEGER NOT NULL,
+    "size" INTEGER NOT NULL,
+    "body" BYTEA NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "raw_payloads_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE UNIQUE INDEX "raw_payloads_run_id_category_page_key" ON "raw_payloads"("run_id", "category", "page");
+
+-- AddForeignKey
+ALTER TABLE "raw_payloads" ADD CONSTRAINT "raw_payloads_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs"("id") ON DELETE CASCADE ON UPDATE CASCADE;
@@ -0,0 +1,7 @@
+-- AlterTable
+ALTER TABLE "products" ADD COLUMN     "available" BOOLEAN,
+ADD COLUMN     "breadcrumb" TEXT,
+ADD COLUMN     "image_url" TEXT,
+ADD COLUMN     "seller" TEXT,
+ADD COLUMN     "slug" TEXT,
+ADD COLUMN     "url" TEXT;
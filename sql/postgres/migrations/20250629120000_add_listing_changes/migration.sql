@@ -0,0 +1,19 @@
+-- CreateTable
+CREATE TABLE "listing_changes" (
+    "id" TEXT NOT NULL,
+    "product_id" TEXT NOT NULL,
+    "run_id" TEXT,
+    "status" TEXT NOT NULL,
+    "created_at" TIMESTAMP(3) NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT "listing_changes_pkey" PRIMARY KEY ("id")
+);
+
+-- CreateIndex
+CREATE INDEX "listing_changes_product_id_created_at_idx" ON "listing_changes"("product_id", "created_at");
+
+-- AddForeignKey
+ALTER TABLE "listing_changes" ADD CONSTRAINT "listing_changes_product_id_fkey" FOREIGN KEY ("product_id") REFERENCES "products"("id") ON DELETE RESTRICT ON UPDATE CASCADE;
+
+-- AddForeignKey
+ALTER TABLE "listing_changes" ADD CONSTRAINT "listing_changes_run_id_fkey" FOREIGN KEY ("run_id") REFERENCES "scrape_runs"("id") ON DELETE SET NULL ON UPDATE CASCADE;
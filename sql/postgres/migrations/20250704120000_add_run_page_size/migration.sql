@@ -0,0 +1,7 @@
+-- AlterTable
+ALTER TABLE "scrape_runs" ADD COLUMN     "page_size" INTEGER;
+
+-- Backfill the page size of the runs that saved pages
+UPDATE "scrape_runs" SET "page_size" = (
+    SELECT MAX("size") FROM "raw_payloads" WHERE "raw_payloads"."run_id" = "scrape_runs"."id"
+);